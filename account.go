@@ -0,0 +1,55 @@
+package babynames
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the minimum number of characters required in a local account password.
+const MinPasswordLength = 8
+
+var (
+	// ErrAccountExists is returned when creating a local account for an e-mail address that already has one.
+	ErrAccountExists = errors.New("account already exists")
+
+	// ErrPasswordTooShort is returned when a local account password is shorter than MinPasswordLength.
+	ErrPasswordTooShort = fmt.Errorf("password must be at least %d characters long", MinPasswordLength)
+)
+
+// ValidatePassword checks that a password can be used for a local account.
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return ErrPasswordTooShort
+	}
+	return nil
+}
+
+// HashPassword validates a password and hashes it for storing with a local account.
+func HashPassword(password string) (string, error) {
+	if err := ValidatePassword(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("Unable to hash password: %v", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword checks a password against the hash stored with a local account.
+func CheckPassword(hash, password string) bool {
+	return hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// LoginTokenPurpose identifies what a one-time login token can be used for.
+type LoginTokenPurpose string
+
+const (
+	// MagicLinkToken signs the user in directly
+	MagicLinkToken LoginTokenPurpose = "magic_link"
+
+	// PasswordResetToken lets the user choose a new password
+	PasswordResetToken LoginTokenPurpose = "password_reset"
+)
@@ -0,0 +1,28 @@
+package babynames
+
+// Agreement compares the votes of the two roles on the names both of them have voted on.
+type Agreement struct {
+	Seen         int
+	BothLiked    int
+	BothDisliked int
+	OnlyLiked    map[Role]int
+	Rate         float64
+	Kappa        float64
+	Opposites    []OppositeVote
+	Likes        map[Role]int
+	Matches      int
+}
+
+// OppositeVote describes a name one role liked and the other disliked.
+type OppositeVote struct {
+	Name    string
+	LikedBy Role
+}
+
+// MatchRate returns the share of the role's likes that turned into matches.
+func (a Agreement) MatchRate(role Role) float64 {
+	if a.Likes[role] == 0 {
+		return 0
+	}
+	return float64(a.Matches) / float64(a.Likes[role])
+}
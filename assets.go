@@ -0,0 +1,8 @@
+package babynames
+
+import "embed"
+
+// Assets holds the templates and static files of the web app, so the server can be started from anywhere.
+//
+//go:embed templates static
+var Assets embed.FS
@@ -2,16 +2,21 @@ package babynames
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
 // DislikesBeforeRemoved sets number of times a name should have to be disliked before no longer showing up
 const DislikesBeforeRemoved = 2
 
+// ErrNotLiked is returned when tagging a name the role hasn't liked, as only liked names can be tagged.
+var ErrNotLiked = errors.New("name is not liked")
+
 // LikedName describes a name that has been liked.
 type LikedName struct {
 	Name       string
 	Superliked bool
+	Rating     int
 	LikedAt    time.Time
 }
 
@@ -23,16 +28,40 @@ type DislikedName struct {
 	LastDislike  time.Time
 }
 
-// Match describes a name that has been liked by both roles.
+// Match describes a name that has been liked by both roles. The score is the strength of the match, explained by
+// its score factors.
 type Match struct {
-	Name  string
-	Roles map[Role]MatchRole
+	Name        string
+	Roles       map[Role]MatchRole
+	SharedNotes int
+	Score       int
 }
 
 // MatchRole describes when and how a role participated in a match.
 type MatchRole struct {
-	LikedAt    time.Time
-	Superliked bool
+	LikedAt            time.Time
+	Superliked         bool
+	Rating             int
+	PreviouslyDisliked bool
+}
+
+// Score returns how much the role likes the name. Rated likes score their rating, while likes made outside of
+// rating mode score as the lowest rating that counts as a like, or as the highest rating if superliked.
+func (r MatchRole) Score() int {
+	switch {
+	case r.Rating > 0:
+		return r.Rating
+	case r.Superliked:
+		return MaxRating
+	default:
+		return RatingLikeThreshold
+	}
+}
+
+// QueuedName describes an upcoming name in a role's queue.
+type QueuedName struct {
+	Name     string
+	Dislikes int
 }
 
 // Stats represents the progress of a role.
@@ -44,19 +73,97 @@ type Stats struct {
 	Matched  int
 }
 
+// Progress describes how far a role has come through the names, which is cheaper to look up than Stats.
+type Progress struct {
+	Total  int
+	Queued int
+}
+
+// DailyStats describes the swiping activity of a role on a single day.
+type DailyStats struct {
+	Day        time.Time
+	Swiped     int
+	Liked      int
+	Superliked int
+	Disliked   int
+	Snoozed    int
+	Matched    int
+}
+
+// LikeRatio returns the share of the day's decisions that were likes or superlikes. Snoozes put off deciding, so
+// they don't count.
+func (s DailyStats) LikeRatio() float64 {
+	decided := s.Swiped - s.Snoozed
+	if decided == 0 {
+		return 0
+	}
+	return float64(s.Liked+s.Superliked) / float64(decided)
+}
+
 // Repository defines the data access layer behavior.
 type Repository interface {
 	ImportNames(context.Context, []string) error
 	Like(context.Context, Role, string) error
 	Superlike(context.Context, Role, string) error
 	UndoLike(context.Context, Role, string) error
+	Rate(context.Context, Role, string, int) error
 	Dislike(context.Context, Role, string) (int, error)
+	ApplySwipes(context.Context, Role, []Swipe) error
 	UndoDislike(context.Context, Role, string) error
+	Snooze(context.Context, Role, string, Snooze) error
+	UndoSnooze(context.Context, Role, string) error
+	GetSnoozedNames(context.Context, Role) ([]SnoozedName, error)
 	GetPendingSuperlike(context.Context, Role) (string, error)
+	GetPendingSuperlikes(context.Context, Role) ([]string, error)
 	GetAndAcknowledgeUnseenMatch(context.Context, Role) (string, error)
 	GetNextName(context.Context, Role) (string, int, error)
+	GetNextNames(context.Context, Role, int) ([]QueuedName, error)
 	GetLikedNames(context.Context, Role) ([]LikedName, error)
 	GetDislikedNames(context.Context, Role) ([]DislikedName, error)
 	GetMatches(context.Context, Role) ([]Match, error)
+	IsMatch(context.Context, string) (bool, error)
 	GetStats(context.Context, Role) (Stats, error)
+	GetProgress(context.Context, Role) (Progress, error)
+	CountMatches(context.Context) (int, error)
+	GetDailyStats(context.Context, Role) ([]DailyStats, error)
+	GetAgreement(context.Context) (Agreement, error)
+	AddNote(context.Context, Role, string, string, bool) error
+	DeleteNote(context.Context, Role, int) error
+	GetNotes(context.Context, Role) ([]Note, error)
+	TagName(context.Context, Role, string, string) error
+	UntagName(context.Context, Role, string, string) error
+	GetTags(context.Context, Role) (map[string][]string, error)
+	CreateAccount(context.Context, string, string) error
+	CreateInvitedAccount(context.Context, string, string, Role, bool) error
+	GetPasswordHash(context.Context, string) (string, error)
+	SetPasswordHash(context.Context, string, string) error
+	CreateLoginToken(context.Context, string, LoginTokenPurpose, string, time.Time) error
+	ConsumeLoginToken(context.Context, LoginTokenPurpose, string) (string, error)
+	GetRoleForEmail(context.Context, string) (Role, error)
+	AddMember(context.Context, string, Role) error
+	GetMembers(context.Context) ([]Member, error)
+	AddGuest(context.Context, string) error
+	IsGuest(context.Context, string) (bool, error)
+	GetGuests(context.Context) ([]Guest, error)
+	GuestVote(context.Context, string, string, GuestVote) error
+	GetNextGuestName(context.Context, string) (string, error)
+	GetGuestOpinions(context.Context) (map[string]GuestOpinion, error)
+	GetDigestSettings(context.Context, Role) (DigestSettings, error)
+	SetDigestFrequency(context.Context, Role, DigestFrequency) error
+	MarkDigestSent(context.Context, Role, time.Time) error
+	GetRatingMode(context.Context, Role) (bool, error)
+	SetRatingMode(context.Context, Role, bool) error
+	GetWebhooks(context.Context) ([]Webhook, error)
+	AddWebhook(context.Context, string, string) error
+	DeleteWebhook(context.Context, int) error
+	LogWebhookDelivery(context.Context, WebhookDelivery) error
+	GetWebhookDeliveries(context.Context, int) ([]WebhookDelivery, error)
+	PruneWebhookDeliveries(context.Context, time.Time) error
+	GetEvents(context.Context, int64, int) ([]Event, error)
+	GetLastEventID(context.Context) (int64, error)
+	GetEventCursor(context.Context, string) (int64, error)
+	SetEventCursor(context.Context, string, int64) error
+	PruneEvents(context.Context, time.Time) error
+	Backup(context.Context) (Backup, error)
+	Restore(context.Context, Backup, RestoreMode) error
 }
@@ -0,0 +1,167 @@
+package babynames
+
+import (
+	"fmt"
+	"time"
+)
+
+// BackupVersion is the version of the backup format written. It is bumped whenever the format changes, and
+// backups written by a later version can't be restored.
+const BackupVersion = 1
+
+// RestoreMode decides what happens to the data already in the database when restoring a backup.
+type RestoreMode string
+
+const (
+	// RestoreMerge keeps the existing names and votes, with the votes in the backup winning for the same names.
+	RestoreMerge RestoreMode = "merge"
+
+	// RestoreReplace replaces the existing names and votes with the ones in the backup.
+	RestoreReplace RestoreMode = "replace"
+)
+
+// Backup is a copy of the household's names and everything mom and dad have done with them. Accounts, members,
+// guests and settings are left out.
+type Backup struct {
+	Version             int                       `json:"version"`
+	CreatedAt           time.Time                 `json:"created_at"`
+	Names               []string                  `json:"names"`
+	Likes               []BackupLike              `json:"likes"`
+	Dislikes            []BackupDislike           `json:"dislikes"`
+	AcknowledgedMatches []BackupAcknowledgedMatch `json:"acknowledged_matches"`
+	Snoozes             []BackupSnooze            `json:"snoozes"`
+	Notes               []BackupNote              `json:"notes"`
+	Tags                []BackupTag               `json:"tags"`
+	Swipes              []BackupSwipe             `json:"swipes"`
+}
+
+// BackupLike is a like in a backup. Rating is 0 unless the name was rated.
+type BackupLike struct {
+	Role               Role      `json:"role"`
+	Name               string    `json:"name"`
+	LikedAt            time.Time `json:"liked_at"`
+	Superlike          bool      `json:"superlike"`
+	Rating             int       `json:"rating,omitempty"`
+	PreviouslyDisliked bool      `json:"previously_disliked"`
+}
+
+// BackupDislike is a dislike in a backup, along with how many times the name was disliked.
+type BackupDislike struct {
+	Role    Role      `json:"role"`
+	Name    string    `json:"name"`
+	FirstAt time.Time `json:"first_at"`
+	LastAt  time.Time `json:"last_at"`
+	Times   int       `json:"times"`
+}
+
+// BackupAcknowledgedMatch is a match a role has been shown, in a backup.
+type BackupAcknowledgedMatch struct {
+	Role           Role      `json:"role"`
+	Name           string    `json:"name"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+}
+
+// BackupSnooze is a snoozed name in a backup. Until is zero unless the name is hidden until then.
+type BackupSnooze struct {
+	Role          Role      `json:"role"`
+	Name          string    `json:"name"`
+	Times         int       `json:"times"`
+	LastSnoozedAt time.Time `json:"last_snoozed_at"`
+	Until         time.Time `json:"until"`
+}
+
+// BackupNote is a note in a backup.
+type BackupNote struct {
+	Role      Role      `json:"role"`
+	Name      string    `json:"name"`
+	Text      string    `json:"text"`
+	Shared    bool      `json:"shared"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupTag is a tag in a backup.
+type BackupTag struct {
+	Role     Role      `json:"role"`
+	Name     string    `json:"name"`
+	Tag      string    `json:"tag"`
+	TaggedAt time.Time `json:"tagged_at"`
+}
+
+// BackupSwipe is an entry of the swipe log in a backup.
+type BackupSwipe struct {
+	Role     Role      `json:"role"`
+	Name     string    `json:"name"`
+	Action   string    `json:"action"`
+	SwipedAt time.Time `json:"swiped_at"`
+}
+
+// Validate checks that the backup can be restored, so a broken backup is rejected before anything is changed.
+func (b *Backup) Validate() error {
+	if b.Version < 1 || b.Version > BackupVersion {
+		return fmt.Errorf("Unsupported backup version %d, expected version %d or earlier", b.Version, BackupVersion)
+	}
+
+	names := make(map[string]bool, len(b.Names))
+	for _, name := range b.Names {
+		if names[name] {
+			return fmt.Errorf("Duplicate name '%s'", name)
+		}
+		names[name] = true
+	}
+	check := func(kind string, role Role, name string) error {
+		if role != MomRole && role != DadRole {
+			return fmt.Errorf("Unknown role %d in %s of '%s'", role, kind, name)
+		}
+		if !names[name] {
+			return fmt.Errorf("Unknown name '%s' in %s", name, kind)
+		}
+		return nil
+	}
+
+	// A role can't both like and dislike a name
+	type vote struct {
+		role Role
+		name string
+	}
+	liked := make(map[vote]bool, len(b.Likes))
+	for _, like := range b.Likes {
+		if err := check("like", like.Role, like.Name); err != nil {
+			return err
+		}
+		liked[vote{like.Role, like.Name}] = true
+	}
+	for _, dislike := range b.Dislikes {
+		if err := check("dislike", dislike.Role, dislike.Name); err != nil {
+			return err
+		}
+		if liked[vote{dislike.Role, dislike.Name}] {
+			return fmt.Errorf("'%s' is both liked and disliked by %s", dislike.Name, RoleName(dislike.Role))
+		}
+	}
+	for _, match := range b.AcknowledgedMatches {
+		if err := check("acknowledged match", match.Role, match.Name); err != nil {
+			return err
+		}
+	}
+	for _, snooze := range b.Snoozes {
+		if err := check("snooze", snooze.Role, snooze.Name); err != nil {
+			return err
+		}
+	}
+	for _, note := range b.Notes {
+		if err := check("note", note.Role, note.Name); err != nil {
+			return err
+		}
+	}
+	for _, tag := range b.Tags {
+		if err := check("tag", tag.Role, tag.Name); err != nil {
+			return err
+		}
+	}
+	for _, swipe := range b.Swipes {
+		if err := check("swipe", swipe.Role, swipe.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
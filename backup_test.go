@@ -0,0 +1,44 @@
+package babynames
+
+import "testing"
+
+func TestBackupValidateRejectsConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		backup Backup
+	}{
+		{
+			name: "duplicate name",
+			backup: Backup{
+				Version: BackupVersion,
+				Names:   []string{"Emma", "Emma"},
+			},
+		},
+		{
+			name: "liked and disliked by the same role",
+			backup: Backup{
+				Version:  BackupVersion,
+				Names:    []string{"Emma"},
+				Likes:    []BackupLike{{Role: MomRole, Name: "Emma"}},
+				Dislikes: []BackupDislike{{Role: MomRole, Name: "Emma", Times: 1}},
+			},
+		},
+	}
+	for _, test := range tests {
+		if err := test.backup.Validate(); err == nil {
+			t.Errorf("Expected a backup with a %s to be rejected", test.name)
+		}
+	}
+}
+
+func TestBackupValidateAllowsRolesToDisagree(t *testing.T) {
+	backup := Backup{
+		Version:  BackupVersion,
+		Names:    []string{"Emma"},
+		Likes:    []BackupLike{{Role: MomRole, Name: "Emma"}},
+		Dislikes: []BackupDislike{{Role: DadRole, Name: "Emma", Times: 1}},
+	}
+	if err := backup.Validate(); err != nil {
+		t.Errorf("Expected one role liking and the other disliking a name to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tanordheim/babyname-tinder"
+	"github.com/tanordheim/babyname-tinder/psql"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// runAccount sets the password of a household member's local account. Without a mailer, members can't prove they
+// own their e-mail address when signing up, so whoever runs the server sets their first password instead.
+func runAccount(databaseURL string, args []string) error {
+	if len(args) != 2 || args[0] != "password" {
+		return errors.New("Expected 'account password <email>'")
+	}
+	emailAddress := args[1]
+
+	repo := psql.NewRepository(databaseURL)
+	defer repo.Close()
+
+	ctx := context.Background()
+	if _, err := repo.GetRoleForEmail(ctx, emailAddress); err != nil {
+		if err != babynames.ErrNotMember {
+			return err
+		}
+		guest, err := repo.IsGuest(ctx, emailAddress)
+		if err != nil {
+			return err
+		}
+		if !guest {
+			return fmt.Errorf("'%s' is not a member or guest of the household", emailAddress)
+		}
+	}
+
+	password, err := readPassword(fmt.Sprintf("Password for %s: ", emailAddress))
+	if err != nil {
+		return err
+	}
+	hash, err := babynames.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := repo.SetPasswordHash(ctx, emailAddress, hash); err != nil {
+		return err
+	}
+	fmt.Printf("Password set for %s\n", emailAddress)
+	return nil
+}
+
+// readPassword reads a password from the terminal without echoing it, or a line from stdin when it is piped in.
+func readPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return string(password), err
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tanordheim/babyname-tinder"
+	"github.com/tanordheim/babyname-tinder/psql"
+)
+
+// runBackup writes a backup of the database to the file given, or to stdout.
+func runBackup(databaseURL string, args []string) error {
+	if len(args) > 1 {
+		return errors.New("Expected 'backup [file]'")
+	}
+
+	repo := psql.NewRepository(databaseURL)
+	defer repo.Close()
+
+	backup, err := repo.Backup(context.Background())
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if len(args) == 1 {
+		f, err := os.Create(args[0])
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Unable to create backup file '%s'", args[0]))
+		}
+		defer f.Close()
+		out = f
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(backup); err != nil {
+		return errors.Wrap(err, "Unable to write backup")
+	}
+	return nil
+}
+
+// runRestore restores a backup from the file given, merging it into the database unless told to replace.
+func runRestore(databaseURL string, autoMigrate bool, args []string) error {
+	mode := babynames.RestoreMerge
+	if len(args) == 2 && args[0] == "-replace" {
+		mode = babynames.RestoreReplace
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return errors.New("Expected 'restore [-replace] <file>'")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Unable to open backup file '%s'", args[0]))
+	}
+	defer f.Close()
+
+	var backup babynames.Backup
+	if err := json.NewDecoder(f).Decode(&backup); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Unable to read backup file '%s'", args[0]))
+	}
+	if err := backup.Validate(); err != nil {
+		return err
+	}
+
+	// Restoring into a fresh database is common when moving hosts, so the schema is brought up to date first
+	if autoMigrate {
+		if err := psql.Migrate(context.Background(), databaseURL); err != nil {
+			return err
+		}
+	}
+
+	repo := psql.NewRepository(databaseURL)
+	defer repo.Close()
+
+	if err := repo.Restore(context.Background(), backup, mode); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d names, %d likes and %d dislikes (%s)\n", len(backup.Names), len(backup.Likes), len(backup.Dislikes), mode)
+	return nil
+}
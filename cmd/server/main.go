@@ -1,46 +1,215 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
-	"strconv"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/tanordheim/babyname-tinder"
+	"github.com/tanordheim/babyname-tinder/config"
+	"github.com/tanordheim/babyname-tinder/digest"
+	"github.com/tanordheim/babyname-tinder/events"
 	"github.com/tanordheim/babyname-tinder/http"
+	"github.com/tanordheim/babyname-tinder/mail"
+	"github.com/tanordheim/babyname-tinder/metrics"
 	"github.com/tanordheim/babyname-tinder/psql"
+	"github.com/tanordheim/babyname-tinder/webhook"
 )
 
-func getConfig(name string) string {
-	val := os.Getenv(name)
-	if val == "" {
-		panic(fmt.Errorf("Environment variable %s is not set", name))
+func getAuthProvider(cfg config.Auth, siteURL string) (http.AuthProvider, error) {
+	switch cfg.Provider {
+	case config.Auth0Provider:
+		return http.NewAuth0Provider(cfg.Auth0Domain, siteURL, cfg.ClientID, cfg.ClientSecret), nil
+	case config.GitHubProvider:
+		return http.NewGitHubProvider(siteURL, cfg.ClientID, cfg.ClientSecret), nil
+	case config.GoogleProvider:
+		authProvider, err := http.NewGoogleProvider(context.Background(), siteURL, cfg.ClientID, cfg.ClientSecret)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to set up Google authentication")
+		}
+		return authProvider, nil
+	case config.OIDCProvider:
+		authProvider, err := http.NewOIDCProvider(context.Background(), cfg.OIDCIssuerURL, siteURL, cfg.ClientID, cfg.ClientSecret)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to set up OpenID Connect authentication")
+		}
+		return authProvider, nil
+	default:
+		return nil, nil
 	}
+}
 
-	return val
+func getMailer(cfg config.Mail) babynames.Mailer {
+	switch cfg.Mailer {
+	case config.SMTPMailer:
+		return mail.NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	case config.FileMailer:
+		return mail.NewFileMailer(cfg.Dir, cfg.From)
+	default:
+		return nil
+	}
 }
 
-func main() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "5000"
+// addConfiguredMembers adds the members configured for the household, for installations that predate
+// invitations.
+func addConfiguredMembers(repo babynames.Repository, cfg *config.Config) error {
+	members := map[babynames.Role]string{
+		babynames.DadRole: cfg.DadEmail,
+		babynames.MomRole: cfg.MomEmail,
+	}
+	for role, email := range members {
+		if email == "" {
+			continue
+		}
+		if err := repo.AddMember(context.Background(), email, role); err != nil && err != babynames.ErrMemberExists {
+			return errors.Wrap(err, fmt.Sprintf("Unable to add '%s' to the household", email))
+		}
 	}
-	portNumber, err := strconv.Atoi(port)
+	return nil
+}
+
+// checkConfig reports the outcome of loading the configuration, exiting with a non-zero status if it is invalid.
+func checkConfig(err error) {
+	if err == nil {
+		fmt.Println("Configuration is valid")
+		return
+	}
+
+	if problems, ok := err.(config.Problems); ok {
+		fmt.Printf("Found %d problem(s) with the configuration:\n", len(problems))
+		for _, problem := range problems {
+			fmt.Printf("- %s\n", problem)
+		}
+	} else {
+		fmt.Println(err)
+	}
+	os.Exit(1)
+}
+
+// serve runs the app until it receives SIGINT or SIGTERM.
+func serve(cfg *config.Config) error {
+	authProvider, err := getAuthProvider(cfg.Auth, cfg.SiteURL)
 	if err != nil {
-		panic(errors.Wrap(err, fmt.Sprintf("Unable to parse port number '%s'", port)))
+		return err
 	}
-	siteURL := getConfig("SITE_URL")
-	cookieSecret := getConfig("COOKIE_SECRET")
-	oauthClientID := getConfig("OAUTH_CLIENT_ID")
-	oauthClientSecret := getConfig("OAUTH_CLIENT_SECRET")
+	mailer := getMailer(cfg.Mail)
 
-	repo := psql.NewRepository(os.Getenv("DATABASE_URL"))
+	if cfg.AutoMigrate {
+		if err := psql.Migrate(context.Background(), cfg.DatabaseURL); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background workers are waited on before the database is closed, so they aren't cut off mid-query
+	var workers sync.WaitGroup
+	runWorker := func(run func(context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(ctx)
+		}()
+	}
+
+	db := psql.NewRepository(cfg.DatabaseURL)
+	defer func() {
+		stop()
+		workers.Wait()
+		if err := db.Close(); err != nil {
+			logrus.WithError(err).Error("Unable to close the database")
+		}
+	}()
+
+	var repo babynames.Repository = db
+	if cfg.MetricsAddr != "" {
+		repo = metrics.NewRepository(repo)
+		metrics.Register(repo)
+		runWorker(func(ctx context.Context) {
+			if err := metrics.Serve(ctx, cfg.MetricsAddr); err != nil {
+				logrus.WithError(err).Error("Unable to serve metrics")
+			}
+		})
+	}
+
+	if err := addConfiguredMembers(repo, cfg); err != nil {
+		return err
+	}
+	bus := events.NewBus()
+	runWorker(events.NewRelay(repo, bus).Run)
+	runWorker(webhook.NewDispatcher(repo).Run)
+	if mailer != nil {
+		runWorker(digest.NewDigester(repo, mailer, cfg.SiteURL).Run)
+	}
 	server := http.NewServer(
-		portNumber,
-		siteURL,
-		cookieSecret,
-		oauthClientID,
-		oauthClientSecret,
+		cfg.Port,
+		http.Timeouts(cfg.Timeouts),
+		http.SnoozeLengths(cfg.Snooze),
+		cfg.SiteURL,
+		cfg.CookieSecret,
+		cfg.AssetsDir,
+		authProvider,
+		mailer,
+		bus,
 		repo,
+		db,
 	)
-	server.Run()
+	return server.Run(ctx)
+}
+
+func main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "Path to a YAML configuration file, overridden by the environment")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-config file] [config check | migrate up|down [steps]|status|goto <version> | backup [file] | restore [-replace] <file> | account password <email>]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	switch args := flag.Args(); {
+	case len(args) == 0:
+		if err != nil {
+			logrus.WithError(err).Fatal("Unable to load configuration")
+		}
+		if err := serve(cfg); err != nil {
+			logrus.WithError(err).Fatal("Server stopped")
+		}
+		logrus.Info("Server shut down")
+	case len(args) == 2 && args[0] == "config" && args[1] == "check":
+		checkConfig(err)
+	case args[0] == "migrate" || args[0] == "backup" || args[0] == "restore" || args[0] == "account":
+		// These only need the database, so the rest of the configuration may well be incomplete
+		if cfg == nil {
+			logrus.WithError(err).Fatal("Unable to load configuration")
+		}
+		if cfg.DatabaseURL == "" {
+			logrus.Fatal("database_url (DATABASE_URL) is not set")
+		}
+
+		switch args[0] {
+		case "migrate":
+			err = runMigrate(cfg.DatabaseURL, args[1:])
+		case "backup":
+			err = runBackup(cfg.DatabaseURL, args[1:])
+		case "restore":
+			err = runRestore(cfg.DatabaseURL, cfg.AutoMigrate, args[1:])
+		case "account":
+			err = runAccount(cfg.DatabaseURL, args[1:])
+		}
+		if err != nil {
+			logrus.WithError(err).Fatalf("Unable to run %s", args[0])
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tanordheim/babyname-tinder/psql"
+)
+
+// runMigrate manages the schema migrations of the database through the "migrate" subcommands.
+func runMigrate(databaseURL string, args []string) error {
+	if len(args) == 0 {
+		return errors.New("Expected one of 'migrate up', 'migrate down [steps]', 'migrate status' or 'migrate goto <version>'")
+	}
+
+	migrator, err := psql.NewMigrator(context.Background(), databaseURL)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = migrator.Up()
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("Invalid number of steps '%s'", args[1])
+			}
+		}
+		err = migrator.Down(steps)
+	case args[0] == "goto" && len(args) == 2:
+		version, parseErr := strconv.ParseUint(args[1], 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("Invalid version '%s'", args[1])
+		}
+		err = migrator.Goto(uint(version))
+	case args[0] == "status" && len(args) == 1:
+	default:
+		return fmt.Errorf("Unknown migrate command '%s'", args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	return printMigrationStatus(migrator)
+}
+
+func printMigrationStatus(migrator *psql.Migrator) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range status.Migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+		if migration.Version == status.Version && status.Dirty {
+			state = "failed"
+		}
+		fmt.Printf("%-8s %d %s\n", state, migration.Version, migration.Name)
+	}
+
+	if status.Version == 0 {
+		fmt.Println("Schema version: none")
+	} else if status.Dirty {
+		fmt.Printf("Schema version: %d (dirty, the last migration did not complete)\n", status.Version)
+	} else {
+		fmt.Printf("Schema version: %d\n", status.Version)
+	}
+	return nil
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tanordheim/babyname-tinder"
@@ -21,6 +23,9 @@ type disliked struct {
 
 func main() {
 	ctx := context.Background()
+	if err := psql.Migrate(ctx, os.Getenv("DATABASE_URL")); err != nil {
+		panic(errors.Wrap(err, "Unable to migrate the database"))
+	}
 	repo := psql.NewRepository(os.Getenv("DATABASE_URL"))
 
 	// Create some test names
@@ -72,12 +77,22 @@ func main() {
 	assertNextNames := func(role babynames.Role, names ...string) {
 		seenNames := map[string]bool{}
 
-		for i := 0; i < 100; i++ {
-			name, _, err := repo.GetNextName(ctx, role)
-			if err != nil {
-				panic(errors.Wrap(err, fmt.Sprintf("Unable to get next name for role '%v'", role)))
-			}
-			seenNames[name] = true
+		// Names come up in queue order, so fetch more than we expect to find in one go
+		next, err := repo.GetNextNames(ctx, role, len(names)+5)
+		if err != nil {
+			panic(errors.Wrap(err, fmt.Sprintf("Unable to get next names for role '%v'", role)))
+		}
+		for _, name := range next {
+			seenNames[name.Name] = true
+		}
+
+		// The next name should be the first one in the queue
+		name, _, err := repo.GetNextName(ctx, role)
+		if err != nil {
+			panic(errors.Wrap(err, fmt.Sprintf("Unable to get next name for role '%v'", role)))
+		}
+		if len(next) > 0 && name != next[0].Name {
+			panic(fmt.Errorf("Expected next name for role '%v' to be '%s', got '%s'", role, next[0].Name, name))
 		}
 
 		allNames := []string{}
@@ -338,7 +353,481 @@ func main() {
 		)
 	}
 
+	// Check match status of a matched and an unmatched name
+	for name, want := range map[string]bool{"Test Name 0": true, "Test Name 2": false} {
+		match, err := repo.IsMatch(ctx, name)
+		if err != nil {
+			panic(errors.Wrap(err, fmt.Sprintf("Unable to check if name '%s' is a match", name)))
+		}
+		if match != want {
+			panic(fmt.Errorf("Expected name '%s' to have match=%v, got match=%v", name, want, match))
+		}
+	}
+
+	// Every match should have been recorded as created exactly once, along with the superlikes
+	recorded, err := repo.GetEvents(ctx, 0, 1000)
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to get recorded events"))
+	}
+	recordedCount := map[babynames.EventType]map[string]int{}
+	for _, event := range recorded {
+		if recordedCount[event.Type] == nil {
+			recordedCount[event.Type] = map[string]int{}
+		}
+		recordedCount[event.Type][event.Name]++
+	}
+	for _, name := range []string{"Test Name 0", "Test Name 1", "Test Name 3", "Test Name 6", "Test Name 7", "Test Name 8"} {
+		if count := recordedCount[babynames.MatchCreatedEvent][name]; count != 1 {
+			panic(fmt.Errorf("Expected 1 match created event for name '%s', got %d", name, count))
+		}
+	}
+	if len(recordedCount[babynames.MatchCreatedEvent]) != 6 || len(recordedCount[babynames.SuperlikeEvent]) != 2 {
+		panic(fmt.Errorf("Expected 6 match created and 2 superlike events, got %+v", recordedCount))
+	}
+	if lastID, err := repo.GetLastEventID(ctx); err != nil || lastID != recorded[len(recorded)-1].ID {
+		panic(fmt.Errorf("Expected the last event ID to be %d, got %d (%v)", recorded[len(recorded)-1].ID, lastID, err))
+	}
+
+	// New event consumers start after the last recorded event, and keep their place after that
+	if cursor, err := repo.GetEventCursor(ctx, "tests"); err != nil || cursor != recorded[len(recorded)-1].ID {
+		panic(fmt.Errorf("Expected a new event cursor to start at %d, got %d (%v)", recorded[len(recorded)-1].ID, cursor, err))
+	}
+	if err := repo.SetEventCursor(ctx, "tests", recorded[0].ID); err != nil {
+		panic(errors.Wrap(err, "Unable to set event cursor"))
+	}
+	if cursor, err := repo.GetEventCursor(ctx, "tests"); err != nil || cursor != recorded[0].ID {
+		panic(fmt.Errorf("Expected the event cursor to be %d, got %d (%v)", recorded[0].ID, cursor, err))
+	}
+
 	// Check stats after everything is processed
 	assertStats(babynames.DadRole, 8, 2, 0, 6)
 	assertStats(babynames.MomRole, 8, 2, 0, 6)
+	if progress, err := repo.GetProgress(ctx, babynames.DadRole); err != nil || progress != (babynames.Progress{Total: 10, Queued: 0}) {
+		panic(fmt.Errorf("Expected no queued names out of 10 for dad, got %+v (%v)", progress, err))
+	}
+	if matches, err := repo.CountMatches(ctx); err != nil || matches != 6 {
+		panic(fmt.Errorf("Expected 6 matches, got %d (%v)", matches, err))
+	}
+
+	// Create a local account and make sure it can't be created twice
+	if err := repo.CreateAccount(ctx, "dad@example.com", "hash"); err != nil {
+		panic(errors.Wrap(err, "Unable to create account"))
+	}
+	if err := repo.CreateAccount(ctx, "dad@example.com", "other-hash"); err != babynames.ErrAccountExists {
+		panic(fmt.Errorf("Expected creating a duplicate account to fail with '%v', got '%v'", babynames.ErrAccountExists, err))
+	}
+	if err := repo.SetPasswordHash(ctx, "dad@example.com", "new-hash"); err != nil {
+		panic(errors.Wrap(err, "Unable to set password hash"))
+	}
+	if hash, err := repo.GetPasswordHash(ctx, "dad@example.com"); err != nil || hash != "new-hash" {
+		panic(fmt.Errorf("Expected password hash to be 'new-hash', got '%s' (%v)", hash, err))
+	}
+
+	// Issue login tokens and make sure they can only be used once, for their purpose and before they expire
+	assertConsumeLoginToken := func(purpose babynames.LoginTokenPurpose, tokenHash, email string) {
+		actual, err := repo.ConsumeLoginToken(ctx, purpose, tokenHash)
+		if err != nil {
+			panic(errors.Wrap(err, fmt.Sprintf("Unable to consume login token '%s'", tokenHash)))
+		}
+		if actual != email {
+			panic(fmt.Errorf("Expected consuming login token '%s' to return '%s', got '%s'", tokenHash, email, actual))
+		}
+	}
+	if err := repo.CreateLoginToken(ctx, "dad@example.com", babynames.MagicLinkToken, "token-1", time.Now().Add(time.Hour)); err != nil {
+		panic(errors.Wrap(err, "Unable to create login token"))
+	}
+	if err := repo.CreateLoginToken(ctx, "mom@example.com", babynames.PasswordResetToken, "token-2", time.Now().Add(-time.Hour)); err != nil {
+		panic(errors.Wrap(err, "Unable to create login token"))
+	}
+	assertConsumeLoginToken(babynames.PasswordResetToken, "token-1", "")
+	assertConsumeLoginToken(babynames.MagicLinkToken, "token-1", "dad@example.com")
+	assertConsumeLoginToken(babynames.MagicLinkToken, "token-1", "")
+	assertConsumeLoginToken(babynames.PasswordResetToken, "token-2", "")
+
+	// Add household members and make sure neither e-mail addresses nor roles can be taken twice
+	if err := repo.AddMember(ctx, "dad@example.com", babynames.DadRole); err != nil {
+		panic(errors.Wrap(err, "Unable to add household member"))
+	}
+	if err := repo.AddMember(ctx, "someone@example.com", babynames.DadRole); err != babynames.ErrMemberExists {
+		panic(fmt.Errorf("Expected adding a second dad to fail with '%v', got '%v'", babynames.ErrMemberExists, err))
+	}
+	if err := repo.CreateInvitedAccount(ctx, "someone@example.com", "hash", babynames.DadRole, false); err != babynames.ErrMemberExists {
+		panic(fmt.Errorf("Expected signing up as a second dad to fail with '%v', got '%v'", babynames.ErrMemberExists, err))
+	}
+	if hash, err := repo.GetPasswordHash(ctx, "someone@example.com"); err != nil || hash != "" {
+		panic(fmt.Errorf("Expected a failed signup to leave no account behind, got '%s' (%v)", hash, err))
+	}
+	if err := repo.CreateInvitedAccount(ctx, "grandpa@example.com", "hash", 0, true); err != nil {
+		panic(errors.Wrap(err, "Unable to sign up guest"))
+	}
+	if hash, err := repo.GetPasswordHash(ctx, "grandpa@example.com"); err != nil || hash != "hash" {
+		panic(fmt.Errorf("Expected the signed up guest to have an account, got '%s' (%v)", hash, err))
+	}
+	if role, err := repo.GetRoleForEmail(ctx, "dad@example.com"); err != nil || role != babynames.DadRole {
+		panic(fmt.Errorf("Expected role for 'dad@example.com' to be '%v', got '%v' (%v)", babynames.DadRole, role, err))
+	}
+	if _, err := repo.GetRoleForEmail(ctx, "mom@example.com"); err != babynames.ErrNotMember {
+		panic(fmt.Errorf("Expected looking up a non-member to fail with '%v', got '%v'", babynames.ErrNotMember, err))
+	}
+
+	// Add a guest and make sure their votes are aggregated without creating matches
+	if err := repo.AddGuest(ctx, "grandma@example.com"); err != nil {
+		panic(errors.Wrap(err, "Unable to add guest"))
+	}
+	if err := repo.GuestVote(ctx, "grandma@example.com", "Test Name 2", babynames.GuestSuperlike); err != nil {
+		panic(errors.Wrap(err, "Unable to vote as guest"))
+	}
+	if err := repo.GuestVote(ctx, "grandma@example.com", "Test Name 4", babynames.GuestLike); err != nil {
+		panic(errors.Wrap(err, "Unable to vote as guest"))
+	}
+	opinions, err := repo.GetGuestOpinions(ctx)
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to get guest opinions"))
+	}
+	if opinions["Test Name 2"].Superlikes != 1 || opinions["Test Name 4"].Likes != 1 {
+		panic(fmt.Errorf("Expected guest opinions to contain a superlike and a like, got %+v", opinions))
+	}
+
+	// Guests keep seeing the same next name until they've voted on it
+	guestName, err := repo.GetNextGuestName(ctx, "grandma@example.com")
+	if err != nil || guestName == "" || guestName == "Test Name 2" || guestName == "Test Name 4" {
+		panic(fmt.Errorf("Expected a name the guest hasn't voted on, got '%s' (%v)", guestName, err))
+	}
+	if again, err := repo.GetNextGuestName(ctx, "grandma@example.com"); err != nil || again != guestName {
+		panic(fmt.Errorf("Expected the guest's next name to still be '%s', got '%s' (%v)", guestName, again, err))
+	}
+	if err := repo.GuestVote(ctx, "grandma@example.com", guestName, babynames.GuestDislike); err != nil {
+		panic(errors.Wrap(err, "Unable to vote as guest"))
+	}
+	if next, err := repo.GetNextGuestName(ctx, "grandma@example.com"); err != nil || next == guestName {
+		panic(fmt.Errorf("Expected the guest to move on from '%s', got '%s' (%v)", guestName, next, err))
+	}
+	assertStats(babynames.DadRole, 8, 2, 0, 6)
+	assertStats(babynames.MomRole, 8, 2, 0, 6)
+
+	// Change digest settings and make sure they're kept apart per role
+	if settings, err := repo.GetDigestSettings(ctx, babynames.MomRole); err != nil || settings.Frequency != babynames.DefaultDigestFrequency {
+		panic(fmt.Errorf("Expected default digest frequency '%s', got %+v (%v)", babynames.DefaultDigestFrequency, settings, err))
+	}
+	if err := repo.SetDigestFrequency(ctx, babynames.DadRole, babynames.NeverDigest); err != nil {
+		panic(errors.Wrap(err, "Unable to set digest frequency"))
+	}
+	sentAt := time.Now().Truncate(time.Second)
+	if err := repo.MarkDigestSent(ctx, babynames.DadRole, sentAt); err != nil {
+		panic(errors.Wrap(err, "Unable to mark digest as sent"))
+	}
+	if settings, err := repo.GetDigestSettings(ctx, babynames.DadRole); err != nil || settings.Frequency != babynames.NeverDigest || !settings.LastSentAt.Equal(sentAt) {
+		panic(fmt.Errorf("Expected digest settings to be '%s' sent at %v, got %+v (%v)", babynames.NeverDigest, sentAt, settings, err))
+	}
+
+	// Add a webhook, log a delivery to it and make sure it shows up in the delivery log
+	if err := repo.AddWebhook(ctx, "http://localhost/hook", "secret"); err != nil {
+		panic(errors.Wrap(err, "Unable to add webhook"))
+	}
+	webhooks, err := repo.GetWebhooks(ctx)
+	if err != nil || len(webhooks) != 1 {
+		panic(fmt.Errorf("Expected 1 webhook, got %+v (%v)", webhooks, err))
+	}
+	err = repo.LogWebhookDelivery(ctx, babynames.WebhookDelivery{
+		WebhookID:   webhooks[0].ID,
+		EventType:   babynames.MatchCreatedEvent,
+		Payload:     "{}",
+		Attempt:     1,
+		StatusCode:  200,
+		Succeeded:   true,
+		AttemptedAt: time.Now(),
+	})
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to log webhook delivery"))
+	}
+	deliveries, err := repo.GetWebhookDeliveries(ctx, 10)
+	if err != nil || len(deliveries) != 1 || deliveries[0].URL != "http://localhost/hook" {
+		panic(fmt.Errorf("Expected 1 delivery to 'http://localhost/hook', got %+v (%v)", deliveries, err))
+	}
+	if err := repo.PruneWebhookDeliveries(ctx, time.Now().Add(-time.Hour)); err != nil {
+		panic(errors.Wrap(err, "Unable to prune webhook deliveries"))
+	}
+	if deliveries, err := repo.GetWebhookDeliveries(ctx, 10); err != nil || len(deliveries) != 1 {
+		panic(fmt.Errorf("Expected pruning older deliveries to keep the recent one, got %+v (%v)", deliveries, err))
+	}
+	if err := repo.PruneWebhookDeliveries(ctx, time.Now().Add(time.Hour)); err != nil {
+		panic(errors.Wrap(err, "Unable to prune webhook deliveries"))
+	}
+	if deliveries, err := repo.GetWebhookDeliveries(ctx, 10); err != nil || len(deliveries) != 0 {
+		panic(fmt.Errorf("Expected every delivery to be pruned, got %+v (%v)", deliveries, err))
+	}
+	if err := repo.DeleteWebhook(ctx, webhooks[0].ID); err != nil {
+		panic(errors.Wrap(err, "Unable to delete webhook"))
+	}
+
+	// Every swipe above happened today, so today should be the last day of the daily stats
+	days, err := repo.GetDailyStats(ctx, babynames.MomRole)
+	if err != nil || len(days) == 0 || days[len(days)-1].Swiped == 0 {
+		panic(fmt.Errorf("Expected swipes today in the daily stats, got %+v (%v)", days, err))
+	}
+
+	// The schema was migrated up front, so the database should be ready
+	if err := repo.CheckReady(ctx); err != nil {
+		panic(errors.Wrap(err, "Expected the database to be ready"))
+	}
+
+	// A backup restored over the same data should leave it as it was, whether merged or replacing it
+	backup, err := repo.Backup(ctx)
+	if err != nil || len(backup.Names) == 0 || len(backup.Likes) == 0 {
+		panic(fmt.Errorf("Expected a backup with names and likes, got %+v (%v)", backup, err))
+	}
+	for _, mode := range []babynames.RestoreMode{babynames.RestoreMerge, babynames.RestoreReplace} {
+		if err := repo.Restore(ctx, backup, mode); err != nil {
+			panic(errors.Wrap(err, fmt.Sprintf("Unable to restore backup (%s)", mode)))
+		}
+		restored, err := repo.Backup(ctx)
+		if err != nil || len(restored.Names) != len(backup.Names) || len(restored.Likes) != len(backup.Likes) || len(restored.Dislikes) != len(backup.Dislikes) || len(restored.Swipes) != len(backup.Swipes) {
+			panic(fmt.Errorf("Expected the restored backup (%s) to match the original, got %+v (%v)", mode, restored, err))
+		}
+	}
+
+	// A backup that conflicts with itself is rejected without changing anything
+	conflicting := babynames.Backup{
+		Version:  babynames.BackupVersion,
+		Names:    []string{"Test Name 0"},
+		Likes:    []babynames.BackupLike{{Role: babynames.DadRole, Name: "Test Name 0"}},
+		Dislikes: []babynames.BackupDislike{{Role: babynames.DadRole, Name: "Test Name 0", Times: 1}},
+	}
+	if err := repo.Restore(ctx, conflicting, babynames.RestoreReplace); err == nil {
+		panic(fmt.Errorf("Expected restoring a name both liked and disliked by the same role to fail"))
+	}
+	conflicting.Names = []string{"Test Name 0", "Test Name 0"}
+	conflicting.Dislikes = nil
+	if err := repo.Restore(ctx, conflicting, babynames.RestoreReplace); err == nil {
+		panic(fmt.Errorf("Expected restoring a backup with duplicate names to fail"))
+	}
+	if restored, err := repo.Backup(ctx); err != nil || len(restored.Names) != len(backup.Names) || len(restored.Likes) != len(backup.Likes) {
+		panic(fmt.Errorf("Expected a rejected backup to leave the data as it was, got %+v (%v)", restored, err))
+	}
+
+	// Apply a batch of swipes, and make sure a batch with an unknown action doesn't apply any of its swipes
+	if err := repo.ImportNames(ctx, []string{"Batch Name 0", "Batch Name 1", "Batch Name 2"}); err != nil {
+		panic(errors.Wrap(err, "Unable to import batch names"))
+	}
+	err = repo.ApplySwipes(ctx, babynames.DadRole, []babynames.Swipe{
+		{Name: "Batch Name 0", Action: babynames.LikeSwipe},
+		{Name: "Batch Name 1", Action: babynames.DislikeSwipe},
+		{Name: "Batch Name 2", Action: babynames.SuperlikeSwipe},
+	})
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to apply swipes"))
+	}
+	err = repo.ApplySwipes(ctx, babynames.DadRole, []babynames.Swipe{
+		{Name: "Batch Name 1", Action: babynames.LikeSwipe},
+		{Name: "Batch Name 2", Action: "shrug"},
+	})
+	if err == nil {
+		panic(fmt.Errorf("Expected applying a swipe with an unknown action to fail"))
+	}
+	dadLiked, err := repo.GetLikedNames(ctx, babynames.DadRole)
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to get liked names"))
+	}
+	batchLiked := map[string]bool{}
+	for _, like := range dadLiked {
+		if like.Name == "Batch Name 0" || like.Name == "Batch Name 1" || like.Name == "Batch Name 2" {
+			batchLiked[like.Name] = like.Superliked
+		}
+	}
+	if len(batchLiked) != 2 || batchLiked["Batch Name 0"] || !batchLiked["Batch Name 2"] {
+		panic(fmt.Errorf("Expected 'Batch Name 0' to be liked and 'Batch Name 2' superliked, got %+v", batchLiked))
+	}
+	assertNextNames(babynames.DadRole, "Batch Name 1")
+
+	// Names snoozed for some days don't count as queued until the snooze is over
+	stats, err := repo.GetStats(ctx, babynames.MomRole)
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to get stats"))
+	}
+	if err := repo.Snooze(ctx, babynames.MomRole, "Batch Name 0", babynames.Snooze{Days: 1}); err != nil {
+		panic(errors.Wrap(err, "Unable to snooze name"))
+	}
+	snoozedStats, err := repo.GetStats(ctx, babynames.MomRole)
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to get stats"))
+	}
+	if snoozedStats.Queued != stats.Queued-1 {
+		panic(fmt.Errorf("Expected %d queued names after snoozing one for a day, got %d", stats.Queued-1, snoozedStats.Queued))
+	}
+
+	// Only real changes end up in the swipe log, and undoing a swipe takes it back out
+	swipedToday := func() int {
+		days, err := repo.GetDailyStats(ctx, babynames.MomRole)
+		if err != nil || len(days) == 0 {
+			panic(fmt.Errorf("Expected daily stats, got %+v (%v)", days, err))
+		}
+		return days[len(days)-1].Swiped
+	}
+	swiped := swipedToday()
+	assertLike(babynames.MomRole, "Batch Name 1")
+	assertLike(babynames.MomRole, "Batch Name 1")
+	if err := repo.Rate(ctx, babynames.MomRole, "Batch Name 1", 5); err != nil {
+		panic(errors.Wrap(err, "Unable to rate name"))
+	}
+	if actual := swipedToday(); actual != swiped+1 {
+		panic(fmt.Errorf("Expected liking and re-rating a name to log 1 swipe, got %d", actual-swiped))
+	}
+	if err := repo.UndoLike(ctx, babynames.MomRole, "Batch Name 1"); err != nil {
+		panic(errors.Wrap(err, "Unable to undo like"))
+	}
+	if err := repo.UndoLike(ctx, babynames.MomRole, "Batch Name 1"); err != nil {
+		panic(errors.Wrap(err, "Unable to undo like"))
+	}
+	if actual := swipedToday(); actual != swiped {
+		panic(fmt.Errorf("Expected undoing the like to take it out of the swipe log, got %d swipes more than before", actual-swiped))
+	}
+
+	// Only liked names can be tagged
+	if err := repo.TagName(ctx, babynames.DadRole, "Batch Name 0", "short"); err != nil {
+		panic(errors.Wrap(err, "Unable to tag name"))
+	}
+	if err := repo.TagName(ctx, babynames.DadRole, "Batch Name 1", "short"); err != babynames.ErrNotLiked {
+		panic(fmt.Errorf("Expected tagging a disliked name to fail with '%v', got '%v'", babynames.ErrNotLiked, err))
+	}
+	tags, err := repo.GetTags(ctx, babynames.DadRole)
+	if err != nil || len(tags["Batch Name 0"]) != 1 || len(tags["Batch Name 1"]) != 0 {
+		panic(fmt.Errorf("Expected only 'Batch Name 0' to be tagged, got %+v (%v)", tags, err))
+	}
+
+	// Ratings at or above the like threshold are likes, lower ones dislikes, and rating a liked name again only
+	// changes its rating
+	if err := repo.ImportNames(ctx, []string{"Rating Name 0", "Rating Name 1"}); err != nil {
+		panic(errors.Wrap(err, "Unable to import rating names"))
+	}
+	assertRate := func(role babynames.Role, name string, rating int) {
+		if err := repo.Rate(ctx, role, name, rating); err != nil {
+			panic(errors.Wrap(err, fmt.Sprintf("Unable to rate name '%s' %d as role '%v'", name, rating, role)))
+		}
+	}
+	assertRate(babynames.MomRole, "Rating Name 0", 4)
+	assertRate(babynames.MomRole, "Rating Name 0", 5)
+	assertRate(babynames.MomRole, "Rating Name 1", 2)
+	momLiked, err := repo.GetLikedNames(ctx, babynames.MomRole)
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to get liked names"))
+	}
+	ratings := map[string][]int{}
+	for _, like := range momLiked {
+		ratings[like.Name] = append(ratings[like.Name], like.Rating)
+	}
+	if len(ratings["Rating Name 0"]) != 1 || ratings["Rating Name 0"][0] != 5 || len(ratings["Rating Name 1"]) != 0 {
+		panic(fmt.Errorf("Expected 'Rating Name 0' to be liked once with 5 stars and 'Rating Name 1' not at all, got %+v", ratings))
+	}
+	momDisliked, err := repo.GetDislikedNames(ctx, babynames.MomRole)
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to get disliked names"))
+	}
+	ratedDislike := false
+	for _, dislike := range momDisliked {
+		if dislike.Name == "Rating Name 1" && dislike.Count == 1 {
+			ratedDislike = true
+		}
+	}
+	if !ratedDislike {
+		panic(fmt.Errorf("Expected 'Rating Name 1' rated 2 stars to be disliked once, got %+v", momDisliked))
+	}
+	assertRate(babynames.DadRole, "Rating Name 0", babynames.RatingLikeThreshold)
+	if match, err := repo.IsMatch(ctx, "Rating Name 0"); err != nil || !match {
+		panic(fmt.Errorf("Expected 'Rating Name 0' rated by both to be a match (%v)", err))
+	}
+
+	// The strength of a match adds up its ratings and how soon after one another both liked it
+	momMatches, err := repo.GetMatches(ctx, babynames.MomRole)
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to get matches"))
+	}
+	var rated *babynames.Match
+	for i := range momMatches {
+		if momMatches[i].Name == "Rating Name 0" {
+			rated = &momMatches[i]
+		}
+	}
+	if rated == nil {
+		panic(fmt.Errorf("Expected 'Rating Name 0' among the matches, got %+v", momMatches))
+	}
+	if score := rated.StrengthScore(); score != 5+babynames.RatingLikeThreshold+2 {
+		panic(fmt.Errorf("Expected 'Rating Name 0' to score %d, got %d (%+v)", 5+babynames.RatingLikeThreshold+2, score, rated.ScoreFactors()))
+	}
+
+	// Earlier dislikes count against a match, shared notes for it, and likes far apart earn no bonus
+	now := time.Now()
+	match := babynames.Match{
+		Name: "Scored Name",
+		Roles: map[babynames.Role]babynames.MatchRole{
+			babynames.MomRole: {LikedAt: now, Superliked: true},
+			babynames.DadRole: {LikedAt: now.Add(-30 * 24 * time.Hour), PreviouslyDisliked: true},
+		},
+		SharedNotes: 3,
+	}
+	factors := match.ScoreFactors()
+	expected := []babynames.ScoreFactor{
+		{Description: "Mom superliked it", Points: babynames.MaxRating},
+		{Description: "Dad liked it", Points: babynames.RatingLikeThreshold},
+		{Description: "Dad disliked it before", Points: -2},
+		{Description: "3 shared notes", Points: 2},
+	}
+	if len(factors) != len(expected) {
+		panic(fmt.Errorf("Expected score factors %+v, got %+v", expected, factors))
+	}
+	for i := range expected {
+		if factors[i] != expected[i] {
+			panic(fmt.Errorf("Expected score factors %+v, got %+v", expected, factors))
+		}
+	}
+	if score := match.StrengthScore(); score != babynames.RatingLikeThreshold-2+babynames.MaxRating+2 {
+		panic(fmt.Errorf("Expected a score of %d, got %d", babynames.RatingLikeThreshold-2+babynames.MaxRating+2, score))
+	}
+	match.Roles[babynames.DadRole] = babynames.MatchRole{LikedAt: now.Add(-3 * 24 * time.Hour)}
+	if score := match.StrengthScore(); score != babynames.RatingLikeThreshold+babynames.MaxRating+1+2 {
+		panic(fmt.Errorf("Expected likes within a week to add 1 point for a score of %d, got %d", babynames.RatingLikeThreshold+babynames.MaxRating+1+2, score))
+	}
+
+	// Compare votes on a known set of names; mom and dad both like 4, both dislike 3 and disagree on 3, so they
+	// agree on 70% of the names where chance alone would agree on 50%, for a kappa of 0.4
+	agreementBackup := babynames.Backup{Version: babynames.BackupVersion}
+	vote := func(name string, momLikes, dadLikes bool) {
+		agreementBackup.Names = append(agreementBackup.Names, name)
+		for role, likes := range map[babynames.Role]bool{babynames.MomRole: momLikes, babynames.DadRole: dadLikes} {
+			if likes {
+				agreementBackup.Likes = append(agreementBackup.Likes, babynames.BackupLike{Role: role, Name: name, LikedAt: now})
+			} else {
+				agreementBackup.Dislikes = append(agreementBackup.Dislikes, babynames.BackupDislike{Role: role, Name: name, FirstAt: now, LastAt: now, Times: 1})
+			}
+		}
+	}
+	for i := 0; i < 10; i++ {
+		vote(fmt.Sprintf("Agreement Name %d", i), i < 6, i < 4 || i == 6)
+	}
+	if err := repo.Restore(ctx, agreementBackup, babynames.RestoreReplace); err != nil {
+		panic(errors.Wrap(err, "Unable to restore votes to compare"))
+	}
+	agreement, err := repo.GetAgreement(ctx)
+	if err != nil {
+		panic(errors.Wrap(err, "Unable to compare votes"))
+	}
+	if agreement.Seen != 10 || agreement.BothLiked != 4 || agreement.BothDisliked != 3 || agreement.OnlyLiked[babynames.MomRole] != 2 || agreement.OnlyLiked[babynames.DadRole] != 1 {
+		panic(fmt.Errorf("Expected 10 names with 4 both liked, 3 both disliked, 2 only mom and 1 only dad liked, got %+v", agreement))
+	}
+	if math.Abs(agreement.Rate-0.7) > 1e-9 || math.Abs(agreement.Kappa-0.4) > 1e-9 {
+		panic(fmt.Errorf("Expected an agreement rate of 0.7 and a kappa of 0.4, got %f and %f", agreement.Rate, agreement.Kappa))
+	}
+	if len(agreement.Opposites) != 3 {
+		panic(fmt.Errorf("Expected 3 names with opposite votes, got %+v", agreement.Opposites))
+	}
+
+	// Liking every name both have seen is perfect agreement, even though chance would have agreed on all of them
+	agreementBackup = babynames.Backup{Version: babynames.BackupVersion}
+	vote("Agreement Name 0", true, true)
+	vote("Agreement Name 1", true, true)
+	if err := repo.Restore(ctx, agreementBackup, babynames.RestoreReplace); err != nil {
+		panic(errors.Wrap(err, "Unable to restore votes to compare"))
+	}
+	if agreement, err := repo.GetAgreement(ctx); err != nil || agreement.Kappa != 1 {
+		panic(fmt.Errorf("Expected a kappa of 1 when both liked every name, got %+v (%v)", agreement, err))
+	}
 }
@@ -0,0 +1,328 @@
+// Package config loads the server configuration from an optional YAML file, with environment variables taking
+// precedence over it.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Config holds the configuration of the server.
+type Config struct {
+	Port         int      `yaml:"port"`
+	SiteURL      string   `yaml:"site_url"`
+	CookieSecret string   `yaml:"cookie_secret"`
+	DatabaseURL  string   `yaml:"database_url"`
+	AutoMigrate  bool     `yaml:"auto_migrate"`
+	MetricsAddr  string   `yaml:"metrics_addr"`
+	AssetsDir    string   `yaml:"assets_dir"`
+	DadEmail     string   `yaml:"dad_email"`
+	MomEmail     string   `yaml:"mom_email"`
+	Auth         Auth     `yaml:"auth"`
+	Mail         Mail     `yaml:"mail"`
+	Timeouts     Timeouts `yaml:"timeouts"`
+	Snooze       Snooze   `yaml:"snooze"`
+}
+
+// Auth configures how users sign in.
+type Auth struct {
+	Provider      string `yaml:"provider"`
+	ClientID      string `yaml:"client_id"`
+	ClientSecret  string `yaml:"client_secret"`
+	Auth0Domain   string `yaml:"auth0_domain"`
+	OIDCIssuerURL string `yaml:"oidc_issuer_url"`
+}
+
+// Mail configures how e-mails are sent. E-mail is disabled if no mailer is set.
+type Mail struct {
+	Mailer       string `yaml:"mailer"`
+	From         string `yaml:"from"`
+	SMTPAddr     string `yaml:"smtp_addr"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	Dir          string `yaml:"dir"`
+}
+
+// Timeouts configures how long the server waits on clients, and on in-flight requests when shutting down.
+type Timeouts struct {
+	Read     time.Duration `yaml:"read"`
+	Write    time.Duration `yaml:"write"`
+	Idle     time.Duration `yaml:"idle"`
+	Shutdown time.Duration `yaml:"shutdown"`
+}
+
+// Snooze configures the lengths names can be snoozed for in the queue.
+type Snooze struct {
+	Swipes int   `yaml:"swipes"`
+	Days   []int `yaml:"days"`
+}
+
+// Authentication providers.
+const (
+	Auth0Provider  = "auth0"
+	OIDCProvider   = "oidc"
+	GitHubProvider = "github"
+	GoogleProvider = "google"
+	LocalProvider  = "local"
+)
+
+// Mailers.
+const (
+	SMTPMailer = "smtp"
+	FileMailer = "file"
+)
+
+// Problems lists everything wrong with a configuration.
+type Problems []string
+
+func (p Problems) Error() string {
+	return fmt.Sprintf("Invalid configuration:\n- %s", strings.Join(p, "\n- "))
+}
+
+// Default returns the configuration used for anything that isn't configured.
+func Default() *Config {
+	return &Config{
+		Port:        5000,
+		AutoMigrate: true,
+		Auth: Auth{
+			Provider: Auth0Provider,
+		},
+		Timeouts: Timeouts{
+			Read:     15 * time.Second,
+			Write:    30 * time.Second,
+			Idle:     2 * time.Minute,
+			Shutdown: 20 * time.Second,
+		},
+		Snooze: Snooze{
+			Swipes: 10,
+			Days:   []int{1, 7},
+		},
+	}
+}
+
+// Load reads the configuration from the YAML file at path, if any, and applies the environment on top of it. If
+// the resulting configuration is invalid, the returned error is the Problems found.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Unable to read configuration file '%s'", path))
+		}
+		if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Unable to parse configuration file '%s'", path))
+		}
+	}
+
+	problems := cfg.applyEnv(os.LookupEnv)
+
+	// Paths are appended to the site URL, so a trailing slash would double up
+	cfg.SiteURL = strings.TrimSuffix(cfg.SiteURL, "/")
+
+	problems = append(problems, cfg.Validate()...)
+	if len(problems) > 0 {
+		return cfg, problems
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides the configuration with the environment variables that are set, returning the ones that
+// couldn't be parsed.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) Problems {
+	var problems Problems
+
+	values := []struct {
+		name  string
+		field *string
+	}{
+		{"SITE_URL", &c.SiteURL},
+		{"COOKIE_SECRET", &c.CookieSecret},
+		{"DATABASE_URL", &c.DatabaseURL},
+		{"METRICS_ADDR", &c.MetricsAddr},
+		{"ASSETS_DIR", &c.AssetsDir},
+		{"DAD_EMAIL", &c.DadEmail},
+		{"MOM_EMAIL", &c.MomEmail},
+		{"AUTH_PROVIDER", &c.Auth.Provider},
+		{"OAUTH_CLIENT_ID", &c.Auth.ClientID},
+		{"OAUTH_CLIENT_SECRET", &c.Auth.ClientSecret},
+		{"AUTH0_DOMAIN", &c.Auth.Auth0Domain},
+		{"OIDC_ISSUER_URL", &c.Auth.OIDCIssuerURL},
+		{"MAILER", &c.Mail.Mailer},
+		{"MAIL_FROM", &c.Mail.From},
+		{"SMTP_ADDR", &c.Mail.SMTPAddr},
+		{"SMTP_USERNAME", &c.Mail.SMTPUsername},
+		{"SMTP_PASSWORD", &c.Mail.SMTPPassword},
+		{"MAIL_DIR", &c.Mail.Dir},
+	}
+	for _, v := range values {
+		if val, ok := lookup(v.name); ok && val != "" {
+			*v.field = val
+		}
+	}
+
+	if val, ok := lookup("PORT"); ok && val != "" {
+		port, err := strconv.Atoi(val)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("PORT '%s' is not a number", val))
+		} else {
+			c.Port = port
+		}
+	}
+
+	if val, ok := lookup("AUTO_MIGRATE"); ok && val != "" {
+		autoMigrate, err := strconv.ParseBool(val)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("AUTO_MIGRATE '%s' is not true or false", val))
+		} else {
+			c.AutoMigrate = autoMigrate
+		}
+	}
+
+	if val, ok := lookup("SNOOZE_SWIPES"); ok && val != "" {
+		swipes, err := strconv.Atoi(val)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("SNOOZE_SWIPES '%s' is not a number", val))
+		} else {
+			c.Snooze.Swipes = swipes
+		}
+	}
+
+	if val, ok := lookup("SNOOZE_DAYS"); ok && val != "" {
+		var days []int
+		for _, v := range strings.Split(val, ",") {
+			d, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("SNOOZE_DAYS '%s' is not a list of numbers like '1,7'", val))
+				days = nil
+				break
+			}
+			days = append(days, d)
+		}
+		if days != nil {
+			c.Snooze.Days = days
+		}
+	}
+
+	durations := []struct {
+		name  string
+		field *time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", &c.Timeouts.Read},
+		{"HTTP_WRITE_TIMEOUT", &c.Timeouts.Write},
+		{"HTTP_IDLE_TIMEOUT", &c.Timeouts.Idle},
+		{"SHUTDOWN_TIMEOUT", &c.Timeouts.Shutdown},
+	}
+	for _, d := range durations {
+		if val, ok := lookup(d.name); ok && val != "" {
+			duration, err := time.ParseDuration(val)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s '%s' is not a duration like '30s'", d.name, val))
+				continue
+			}
+			*d.field = duration
+		}
+	}
+
+	return problems
+}
+
+// Validate checks the configuration, returning every problem found.
+func (c *Config) Validate() Problems {
+	var problems Problems
+	require := func(val, name string) {
+		if val == "" {
+			problems = append(problems, fmt.Sprintf("%s is not set", name))
+		}
+	}
+
+	if c.Port < 1 || c.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("port (PORT) %d is not between 1 and 65535", c.Port))
+	}
+	require(c.SiteURL, "site_url (SITE_URL)")
+	if c.SiteURL != "" {
+		if u, err := url.Parse(c.SiteURL); err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("site_url '%s' is not an absolute URL", c.SiteURL))
+		}
+	}
+	require(c.CookieSecret, "cookie_secret (COOKIE_SECRET)")
+	require(c.DatabaseURL, "database_url (DATABASE_URL)")
+	if c.AssetsDir != "" {
+		for _, dir := range []string{"templates", "static"} {
+			if info, err := os.Stat(filepath.Join(c.AssetsDir, dir)); err != nil || !info.IsDir() {
+				problems = append(problems, fmt.Sprintf("assets_dir (ASSETS_DIR) '%s' has no %s directory", c.AssetsDir, dir))
+			}
+		}
+	}
+	for _, email := range []string{c.DadEmail, c.MomEmail} {
+		if email != "" && !strings.Contains(email, "@") {
+			problems = append(problems, fmt.Sprintf("'%s' is not an e-mail address", email))
+		}
+	}
+
+	switch c.Auth.Provider {
+	case LocalProvider:
+	case Auth0Provider, OIDCProvider, GitHubProvider, GoogleProvider:
+		require(c.Auth.ClientID, "auth.client_id (OAUTH_CLIENT_ID)")
+		require(c.Auth.ClientSecret, "auth.client_secret (OAUTH_CLIENT_SECRET)")
+		if c.Auth.Provider == Auth0Provider {
+			require(c.Auth.Auth0Domain, "auth.auth0_domain (AUTH0_DOMAIN)")
+		}
+		if c.Auth.Provider == OIDCProvider {
+			require(c.Auth.OIDCIssuerURL, "auth.oidc_issuer_url (OIDC_ISSUER_URL)")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("auth.provider (AUTH_PROVIDER) '%s' is not one of auth0, oidc, github, google or local", c.Auth.Provider))
+	}
+
+	switch c.Mail.Mailer {
+	case "":
+	case SMTPMailer, FileMailer:
+		require(c.Mail.From, "mail.from (MAIL_FROM)")
+		if c.Mail.Mailer == SMTPMailer {
+			require(c.Mail.SMTPAddr, "mail.smtp_addr (SMTP_ADDR)")
+		}
+		if c.Mail.Mailer == FileMailer {
+			require(c.Mail.Dir, "mail.dir (MAIL_DIR)")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("mail.mailer (MAILER) '%s' is not one of smtp or file", c.Mail.Mailer))
+	}
+
+	timeouts := []struct {
+		name    string
+		timeout time.Duration
+	}{
+		{"timeouts.read", c.Timeouts.Read},
+		{"timeouts.write", c.Timeouts.Write},
+		{"timeouts.idle", c.Timeouts.Idle},
+		{"timeouts.shutdown", c.Timeouts.Shutdown},
+	}
+	for _, t := range timeouts {
+		if t.timeout <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be positive", t.name))
+		}
+	}
+
+	if c.Snooze.Swipes <= 0 {
+		problems = append(problems, "snooze.swipes (SNOOZE_SWIPES) must be positive")
+	}
+	if len(c.Snooze.Days) == 0 {
+		problems = append(problems, "snooze.days (SNOOZE_DAYS) is not set")
+	}
+	for _, d := range c.Snooze.Days {
+		if d <= 0 {
+			problems = append(problems, fmt.Sprintf("snooze.days (SNOOZE_DAYS) %d must be positive", d))
+		}
+	}
+
+	return problems
+}
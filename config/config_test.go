@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func hasProblem(problems Problems, problem string) bool {
+	for _, p := range problems {
+		if p == problem {
+			return true
+		}
+	}
+	return false
+}
+
+// writeConfig writes a configuration file to a temporary directory, returning its path.
+func writeConfig(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("Unable to write configuration file: %v", err)
+	}
+	return path
+}
+
+func TestValidateReportsEveryMissingSetting(t *testing.T) {
+	problems := Default().Validate()
+	for _, missing := range []string{"site_url (SITE_URL)", "cookie_secret (COOKIE_SECRET)", "database_url (DATABASE_URL)", "auth.client_id (OAUTH_CLIENT_ID)", "auth.client_secret (OAUTH_CLIENT_SECRET)", "auth.auth0_domain (AUTH0_DOMAIN)"} {
+		if !hasProblem(problems, missing+" is not set") {
+			t.Errorf("Expected '%s' to be reported missing, got %v", missing, problems)
+		}
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	_, err := Load(writeConfig(t, "timeouts:\n  read: 0s\nsnooze:\n  swipes: 0\n"))
+	problems, ok := err.(Problems)
+	if !ok {
+		t.Fatalf("Expected loading the configuration to fail with Problems, got %v", err)
+	}
+	for _, problem := range []string{"timeouts.read must be positive", "snooze.swipes (SNOOZE_SWIPES) must be positive"} {
+		if !hasProblem(problems, problem) {
+			t.Errorf("Expected '%s' to be reported, got %v", problem, problems)
+		}
+	}
+}
+
+func TestLoadTrimsTrailingSlashFromSiteURL(t *testing.T) {
+	cfg, _ := Load(writeConfig(t, "site_url: https://babynames.example.com/\n"))
+	if cfg.SiteURL != "https://babynames.example.com" {
+		t.Errorf("Expected the trailing slash to be trimmed from the site URL, got '%s'", cfg.SiteURL)
+	}
+
+	t.Setenv("SITE_URL", "https://env.example.com/")
+	cfg, _ = Load("")
+	if cfg.SiteURL != "https://env.example.com" {
+		t.Errorf("Expected the trailing slash to be trimmed from SITE_URL, got '%s'", cfg.SiteURL)
+	}
+}
@@ -0,0 +1,38 @@
+package babynames
+
+import "time"
+
+// DigestFrequency identifies how often a role receives the e-mail digest.
+type DigestFrequency string
+
+const (
+	// NeverDigest opts out of the e-mail digest
+	NeverDigest DigestFrequency = "never"
+
+	// DailyDigest sends the e-mail digest once a day
+	DailyDigest DigestFrequency = "daily"
+
+	// WeeklyDigest sends the e-mail digest once a week
+	WeeklyDigest DigestFrequency = "weekly"
+)
+
+// DefaultDigestFrequency is the digest frequency of roles that haven't chosen one.
+const DefaultDigestFrequency = WeeklyDigest
+
+// Interval returns the time between two digests, or zero if digests are never sent.
+func (f DigestFrequency) Interval() time.Duration {
+	switch f {
+	case DailyDigest:
+		return 24 * time.Hour
+	case WeeklyDigest:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// DigestSettings describes how a role receives the e-mail digest.
+type DigestSettings struct {
+	Frequency  DigestFrequency
+	LastSentAt time.Time
+}
@@ -0,0 +1,171 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// checkInterval is how often the digester looks for digests that are due.
+const checkInterval = time.Hour
+
+// Digester periodically e-mails each member of the household their new matches, the superlikes waiting on them
+// and the progress of their partner.
+type Digester struct {
+	repo    babynames.Repository
+	mailer  babynames.Mailer
+	siteURL string
+}
+
+// NewDigester creates a new digester.
+func NewDigester(repo babynames.Repository, mailer babynames.Mailer, siteURL string) *Digester {
+	return &Digester{
+		repo:    repo,
+		mailer:  mailer,
+		siteURL: siteURL,
+	}
+}
+
+// Run sends digests as they become due, until the context is cancelled.
+func (d *Digester) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.SendDue(ctx, time.Now()); err != nil {
+			logrus.WithError(err).Error("Unable to send digests")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Errors lists the digests that couldn't be sent, one error per member.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("Unable to send %d digest(s):\n- %s", len(e), strings.Join(msgs, "\n- "))
+}
+
+// SendDue sends the digest to every member of the household whose digest is due at the specified time. A member
+// whose digest can't be sent doesn't hold up the digests of the others; the returned error is then the Errors
+// of every member it failed for.
+func (d *Digester) SendDue(ctx context.Context, now time.Time) error {
+	members, err := d.repo.GetMembers(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs Errors
+	for _, member := range members {
+		if err := d.sendDue(ctx, member, now); err != nil {
+			errs = append(errs, errors.Wrap(err, fmt.Sprintf("Unable to send digest to '%s'", member.Email)))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// sendDue sends the digest to a member if it's due at the specified time.
+func (d *Digester) sendDue(ctx context.Context, member babynames.Member, now time.Time) error {
+	settings, err := d.repo.GetDigestSettings(ctx, member.Role)
+	if err != nil {
+		return err
+	}
+
+	interval := settings.Frequency.Interval()
+	if interval == 0 || now.Sub(settings.LastSentAt) < interval {
+		return nil
+	}
+
+	since := settings.LastSentAt
+	if since.IsZero() {
+		since = now.Add(-interval)
+	}
+	msg, err := d.compose(ctx, member, since)
+	if err != nil {
+		return err
+	}
+	if err := d.mailer.Send(ctx, msg); err != nil {
+		return err
+	}
+	return d.repo.MarkDigestSent(ctx, member.Role, now)
+}
+
+func (d *Digester) compose(ctx context.Context, member babynames.Member, since time.Time) (babynames.Message, error) {
+	partner := babynames.InverseRole(member.Role)
+
+	matches, err := d.repo.GetMatches(ctx, member.Role)
+	if err != nil {
+		return babynames.Message{}, err
+	}
+	superlikes, err := d.repo.GetPendingSuperlikes(ctx, member.Role)
+	if err != nil {
+		return babynames.Message{}, err
+	}
+	stats, err := d.repo.GetStats(ctx, partner)
+	if err != nil {
+		return babynames.Message{}, errors.Wrap(err, fmt.Sprintf("Unable to get partner progress for digest to '%s'", member.Email))
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "Here's what happened in Babynames since %s.\n\n", since.Format("January 2"))
+
+	newMatches := 0
+	for _, match := range matches {
+		if matchedAt(match).After(since) {
+			if newMatches == 0 {
+				fmt.Fprintf(&body, "New matches:\n")
+			}
+			fmt.Fprintf(&body, "  - %s\n", match.Name)
+			newMatches++
+		}
+	}
+	if newMatches == 0 {
+		fmt.Fprintf(&body, "No new matches.\n")
+	}
+
+	if len(superlikes) > 0 {
+		fmt.Fprintf(&body, "\n%s superliked these names, and is waiting for you to decide:\n", babynames.RoleName(partner))
+		for _, name := range superlikes {
+			fmt.Fprintf(&body, "  - %s\n", name)
+		}
+	}
+
+	seen := stats.Total - stats.Queued
+	fmt.Fprintf(&body, "\n%s has gone through %d of %d names, liking %d and disliking %d.\n", babynames.RoleName(partner), seen, stats.Total, stats.Liked, stats.Disliked)
+	fmt.Fprintf(&body, "\nKeep swiping at %s/\n\nYou can change how often you get this e-mail at %s/settings\n", d.siteURL, d.siteURL)
+
+	return babynames.Message{
+		To:      member.Email,
+		Subject: fmt.Sprintf("Babynames: %d new matches", newMatches),
+		Body:    body.String(),
+	}, nil
+}
+
+// matchedAt returns when a match happened, which is when the last of the roles liked it.
+func matchedAt(match babynames.Match) time.Time {
+	at := time.Time{}
+	for _, role := range match.Roles {
+		if role.LikedAt.After(at) {
+			at = role.LikedAt
+		}
+	}
+	return at
+}
@@ -0,0 +1,45 @@
+package babynames
+
+import "time"
+
+// EventType identifies what happened in an event.
+type EventType string
+
+const (
+	// MatchCreatedEvent is published when a like makes a name liked by both roles
+	MatchCreatedEvent EventType = "match_created"
+
+	// MatchBrokenEvent is published when a like or dislike makes a matched name no longer liked by both roles
+	MatchBrokenEvent EventType = "match_broken"
+
+	// SuperlikeEvent is published when a role superlikes a name
+	SuperlikeEvent EventType = "superlike"
+
+	// ImportCompletedEvent is published when a set of names has been imported
+	ImportCompletedEvent EventType = "import_completed"
+)
+
+// EventTypes lists all the types of events that can be published.
+var EventTypes = []EventType{MatchCreatedEvent, MatchBrokenEvent, SuperlikeEvent, ImportCompletedEvent}
+
+// Event describes something that happened as a result of a role's actions. Import events are not tied to a
+// role or name, and carry the number of imported names in Count instead. Events are recorded in the same
+// transaction as the change that caused them, and numbered in the order they were recorded.
+type Event struct {
+	ID    int64
+	Type  EventType
+	Role  Role
+	Name  string
+	Count int
+	At    time.Time
+}
+
+// EventPublisher defines the behavior for publishing events.
+type EventPublisher interface {
+	Publish(Event)
+}
+
+// EventSubscriber defines the behavior for receiving published events.
+type EventSubscriber interface {
+	Subscribe() (<-chan Event, func())
+}
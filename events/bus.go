@@ -0,0 +1,62 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// subscriberBuffer is the number of events buffered per subscriber before new events are dropped.
+const subscriberBuffer = 16
+
+// Bus is an in-process event bus fanning published events out to all subscribers.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[chan babynames.Event]struct{}
+}
+
+var (
+	_ babynames.EventPublisher  = &Bus{}
+	_ babynames.EventSubscriber = &Bus{}
+)
+
+// NewBus creates a new event bus.
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: map[chan babynames.Event]struct{}{},
+	}
+}
+
+// Publish sends an event to all subscribers. Subscribers that aren't keeping up miss the event rather than
+// blocking the publisher.
+func (b *Bus) Publish(event babynames.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving all events published from now on, and a function that must be called
+// to stop receiving them.
+func (b *Bus) Subscribe() (<-chan babynames.Event, func()) {
+	ch := make(chan babynames.Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
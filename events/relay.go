@@ -0,0 +1,68 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+const (
+	// pollInterval is how often the relay checks for newly recorded events.
+	pollInterval = time.Second
+
+	// pollBatchSize is the number of recorded events read at a time.
+	pollBatchSize = 100
+)
+
+// Relay publishes the events recorded in the repository, so subscribers in this process hear about them no
+// matter which request or process caused them.
+type Relay struct {
+	repo      babynames.Repository
+	publisher babynames.EventPublisher
+}
+
+// NewRelay creates a new relay publishing the events recorded in repo to publisher.
+func NewRelay(repo babynames.Repository, publisher babynames.EventPublisher) *Relay {
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+	}
+}
+
+// Run publishes events as they are recorded, starting with the ones recorded after it was started, until the
+// context is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	lastID, err := r.repo.GetLastEventID(ctx)
+	for err != nil {
+		logrus.WithError(err).Error("Unable to get the last recorded event")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+		lastID, err = r.repo.GetLastEventID(ctx)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		events, err := r.repo.GetEvents(ctx, lastID, pollBatchSize)
+		if err != nil {
+			logrus.WithError(err).Error("Unable to get recorded events")
+			continue
+		}
+		for _, event := range events {
+			r.publisher.Publish(event)
+			lastID = event.ID
+		}
+	}
+}
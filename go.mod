@@ -1,17 +1,37 @@
 module github.com/tanordheim/babyname-tinder
 
+go 1.20
+
 require (
 	github.com/fatih/motion v0.0.0-20180408211639-218875ebe238 // indirect
 	github.com/golang-migrate/migrate v3.5.4+incompatible // indirect
 	github.com/golang-migrate/migrate/v4 v4.1.0
-	github.com/gorilla/handlers v1.4.0
 	github.com/gorilla/mux v1.6.2
 	github.com/gorilla/sessions v1.1.3
 	github.com/jmoiron/sqlx v1.2.0
 	github.com/kisielk/errcheck v1.1.0 // indirect
 	github.com/lib/pq v1.0.0
 	github.com/pkg/errors v0.8.0
+	github.com/prometheus/client_golang v0.9.2
+	github.com/sirupsen/logrus v1.2.0
 	github.com/zmb3/gogetdoc v0.0.0-20181120020305-71611d8dcf25 // indirect
+	golang.org/x/crypto v0.0.0-20181203042331-505ab145d0a9
 	golang.org/x/oauth2 v0.0.0-20181128211412-28207608b838
 	golang.org/x/tools v0.0.0-20181201035826-d0ca3933b724 // indirect
+	gopkg.in/yaml.v2 v2.2.2
+)
+
+require (
+	github.com/beorn7/perks v0.0.0-20180321164747-3a771d992973 // indirect
+	github.com/golang/protobuf v1.2.0 // indirect
+	github.com/gorilla/context v1.1.1 // indirect
+	github.com/gorilla/securecookie v1.1.1 // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/prometheus/client_model v0.0.0-20180712105110-5c3871d89910 // indirect
+	github.com/prometheus/common v0.0.0-20181126121408-4724e9255275 // indirect
+	github.com/prometheus/procfs v0.0.0-20181204211112-1dc9a6cbc91a // indirect
+	golang.org/x/net v0.0.0-20181201002055-351d144fa1fc // indirect
+	golang.org/x/sys v0.0.0-20181011152604-fa43e7bc11ba // indirect
+	google.golang.org/appengine v1.2.0 // indirect
 )
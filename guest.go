@@ -0,0 +1,31 @@
+package babynames
+
+import "time"
+
+// GuestVote identifies how a guest voted on a name.
+type GuestVote string
+
+const (
+	// GuestLike is a guest liking a name
+	GuestLike GuestVote = "like"
+
+	// GuestSuperlike is a guest superliking a name
+	GuestSuperlike GuestVote = "superlike"
+
+	// GuestDislike is a guest disliking a name
+	GuestDislike GuestVote = "dislike"
+)
+
+// Guest describes a family member or friend that has been invited to vote on names. Guest votes are advisory
+// only, and never create matches.
+type Guest struct {
+	Email    string
+	JoinedAt time.Time
+}
+
+// GuestOpinion aggregates the votes of all guests on a name.
+type GuestOpinion struct {
+	Likes      int
+	Superlikes int
+	Dislikes   int
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/sessions"
+)
+
+type acceptInvitationHandler struct {
+	secret  []byte
+	session sessions.Store
+}
+
+func newAcceptInvitationHandler(secret []byte, session sessions.Store) *acceptInvitationHandler {
+	return &acceptInvitationHandler{
+		secret:  secret,
+		session: session,
+	}
+}
+
+func (h *acceptInvitationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	inv, err := parseInvitationToken(h.secret, r.URL.Query().Get("token"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Remember the invitation until the user has logged in, at which point it's redeemed
+	sess, err := h.session.Get(r, "invitation")
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	sess.Values["role"] = int(inv.Role)
+	sess.Values["guest"] = inv.Guest
+	sess.Values["expires"] = inv.ExpiresAt.Unix()
+	if err := sess.Save(r, w); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
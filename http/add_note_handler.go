@@ -0,0 +1,36 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type addNoteHandler struct {
+	repo babynames.Repository
+}
+
+func newAddNoteHandler(repo babynames.Repository) *addNoteHandler {
+	return &addNoteHandler{
+		repo: repo,
+	}
+}
+
+func (h *addNoteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	name := strings.TrimSpace(r.FormValue("name"))
+	text := strings.TrimSpace(r.FormValue("text"))
+	if name == "" || text == "" {
+		http.Error(w, "A note needs both a name and a text", http.StatusBadRequest)
+		return
+	}
+
+	err := h.repo.AddNote(r.Context(), user.Role, name, text, r.FormValue("shared") != "")
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/notes", http.StatusSeeOther)
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type addWebhookHandler struct {
+	repo babynames.Repository
+}
+
+func newAddWebhookHandler(repo babynames.Repository) *addWebhookHandler {
+	return &addWebhookHandler{
+		repo: repo,
+	}
+}
+
+func (h *addWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	webhookURL := strings.TrimSpace(r.FormValue("url"))
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		http.Error(w, "Invalid webhook URL", http.StatusBadRequest)
+		return
+	}
+
+	// Every webhook gets its own secret for signing payloads
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		serverError(w, r, errors.Wrap(err, "Unable to generate webhook secret"))
+		return
+	}
+	secret := hex.EncodeToString(buf)
+
+	if err := h.repo.AddWebhook(r.Context(), webhookURL, secret); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/webhooks", http.StatusSeeOther)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type agreementHandler struct {
+	template *pageTemplate
+	repo     babynames.Repository
+}
+
+type agreementModel struct {
+	Seen         int
+	BothLiked    int
+	BothDisliked int
+	Rate         string
+	Kappa        string
+	Verdict      string
+	Roles        []agreementRoleModel
+	Opposites    []oppositeVoteModel
+}
+
+type agreementRoleModel struct {
+	Who       string
+	Likes     int
+	OnlyLiked int
+	MatchRate string
+}
+
+type oppositeVoteModel struct {
+	Name    string
+	LikedBy string
+}
+
+func newAgreementHandler(repo babynames.Repository) *agreementHandler {
+	return &agreementHandler{
+		template: parseTemplate("agreement"),
+		repo:     repo,
+	}
+}
+
+func (h *agreementHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	agreement, err := h.repo.GetAgreement(r.Context())
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	model := &agreementModel{
+		Seen:         agreement.Seen,
+		BothLiked:    agreement.BothLiked,
+		BothDisliked: agreement.BothDisliked,
+		Rate:         formatPercentage(agreement.Rate),
+		Kappa:        fmt.Sprintf("%.2f", agreement.Kappa),
+		Verdict:      kappaVerdict(agreement.Kappa),
+	}
+	for _, role := range babynames.Roles {
+		model.Roles = append(model.Roles, agreementRoleModel{
+			Who:       babynames.RoleName(role),
+			Likes:     agreement.Likes[role],
+			OnlyLiked: agreement.OnlyLiked[role],
+			MatchRate: formatPercentage(agreement.MatchRate(role)),
+		})
+	}
+	for _, opposite := range agreement.Opposites {
+		model.Opposites = append(model.Opposites, oppositeVoteModel{
+			Name:    opposite.Name,
+			LikedBy: babynames.RoleName(opposite.LikedBy),
+		})
+	}
+	renderTemplate(w, h.template, model)
+}
+
+// kappaVerdict describes a kappa score using the usual Landis & Koch bands.
+func kappaVerdict(kappa float64) string {
+	switch {
+	case kappa < 0:
+		return "less than chance"
+	case kappa <= 0.2:
+		return "slight"
+	case kappa <= 0.4:
+		return "fair"
+	case kappa <= 0.6:
+		return "moderate"
+	case kappa <= 0.8:
+		return "substantial"
+	default:
+		return "almost perfect"
+	}
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+type auth0Provider struct {
+	domain string
+	config *oauth2.Config
+}
+
+// NewAuth0Provider creates an authentication provider signing users in through Auth0.
+func NewAuth0Provider(domain, siteURL, clientID, clientSecret string) AuthProvider {
+	endpoint := oauth2.Endpoint{
+		AuthURL:  fmt.Sprintf("https://%s/authorize", domain),
+		TokenURL: fmt.Sprintf("https://%s/oauth/token", domain),
+	}
+	return &auth0Provider{
+		domain: domain,
+		config: newOAuthConfig(siteURL, clientID, clientSecret, endpoint, "openid", "profile", "email"),
+	}
+}
+
+func (p *auth0Provider) AuthCodeURL(state string) string {
+	aud := oauth2.SetAuthURLParam("audience", fmt.Sprintf("https://%s/userinfo", p.domain))
+	return p.config.AuthCodeURL(state, aud)
+}
+
+func (p *auth0Provider) Authenticate(ctx context.Context, code string) (string, error) {
+	token, err := p.config.Exchange(ctx, code)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to exchange authorization code with Auth0")
+	}
+
+	var profile map[string]interface{}
+	client := p.config.Client(ctx, token)
+	if err := getJSON(client, fmt.Sprintf("https://%s/userinfo", p.domain), &profile); err != nil {
+		return "", err
+	}
+
+	email, ok := profile["email"].(string)
+	if !ok || email == "" {
+		return "", fmt.Errorf("Auth0 profile has no e-mail address")
+	}
+	return email, nil
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// AuthProvider defines an external identity provider users can sign in through.
+type AuthProvider interface {
+	// AuthCodeURL returns the URL the user should be redirected to in order to start the sign in flow.
+	AuthCodeURL(state string) string
+
+	// Authenticate swaps an authorization code for the e-mail address of the signed in user.
+	Authenticate(ctx context.Context, code string) (string, error)
+}
+
+func newOAuthConfig(siteURL, clientID, clientSecret string, endpoint oauth2.Endpoint, scopes ...string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  fmt.Sprintf("%s/callback", siteURL),
+		Scopes:       scopes,
+		Endpoint:     endpoint,
+	}
+}
+
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Unable to request '%s'", url))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected status code %d from '%s'", resp.StatusCode, url)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Unable to decode response from '%s'", url))
+	}
+	return nil
+}
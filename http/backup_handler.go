@@ -0,0 +1,35 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type backupHandler struct {
+	repo babynames.Repository
+}
+
+func newBackupHandler(repo babynames.Repository) *backupHandler {
+	return &backupHandler{
+		repo: repo,
+	}
+}
+
+// ServeHTTP downloads a backup of all names and votes as JSON.
+func (h *backupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	backup, err := h.repo.Backup(r.Context())
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=\"babynames-%s.json\"", backup.CreatedAt.Format("2006-01-02")))
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(backup)
+}
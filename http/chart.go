@@ -0,0 +1,76 @@
+package http
+
+import (
+	"github.com/tanordheim/babyname-tinder"
+)
+
+const (
+	chartWidth  = 600
+	chartHeight = 160
+
+	// chartPadding leaves room below the bars for the axis labels
+	chartPadding = 20
+)
+
+// chartModel describes a server-rendered SVG bar chart with one bar per day.
+type chartModel struct {
+	Title  string
+	Width  int
+	Height int
+	Base   int
+	Max    string
+	First  string
+	Last   string
+	Bars   []chartBar
+}
+
+// chartBar describes a single bar of a chart.
+type chartBar struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	Label  string
+}
+
+// newBarChart builds a bar chart of a value over the days, scaled to the highest value.
+func newBarChart(title string, days []babynames.DailyStats, value func(babynames.DailyStats) float64, format func(float64) string) chartModel {
+	chart := chartModel{
+		Title:  title,
+		Width:  chartWidth,
+		Height: chartHeight,
+		Base:   chartHeight - chartPadding,
+		Max:    format(0),
+		Bars:   make([]chartBar, len(days)),
+	}
+	if len(days) == 0 {
+		return chart
+	}
+	chart.First = days[0].Day.Format("Jan 2")
+	chart.Last = days[len(days)-1].Day.Format("Jan 2")
+
+	max := 0.0
+	for _, day := range days {
+		if v := value(day); v > max {
+			max = v
+		}
+	}
+	chart.Max = format(max)
+
+	barWidth := float64(chartWidth) / float64(len(days))
+	for idx, day := range days {
+		v := value(day)
+		height := 0.0
+		if max > 0 {
+			height = v / max * float64(chart.Base)
+		}
+		chart.Bars[idx] = chartBar{
+			X:      float64(idx) * barWidth,
+			Y:      float64(chart.Base) - height,
+			Width:  barWidth * 0.8,
+			Height: height,
+			Label:  day.Day.Format("Jan 2") + ": " + format(v),
+		}
+	}
+	return chart
+}
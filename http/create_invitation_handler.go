@@ -0,0 +1,65 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type createInvitationHandler struct {
+	template *pageTemplate
+	siteURL  string
+	secret   []byte
+	repo     babynames.Repository
+}
+
+func newCreateInvitationHandler(siteURL string, secret []byte, repo babynames.Repository) *createInvitationHandler {
+	return &createInvitationHandler{
+		template: parseTemplate("invite"),
+		siteURL:  siteURL,
+		secret:   secret,
+		repo:     repo,
+	}
+}
+
+func (h *createInvitationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	model, err := newInviteModel(r, h.repo)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	inv := invitation{
+		Guest:     r.FormValue("role") == guestInvitation,
+		ExpiresAt: time.Now().Add(babynames.InvitationTTL),
+	}
+	if !inv.Guest {
+		roleID, err := strconv.Atoi(r.FormValue("role"))
+		if err != nil {
+			http.Error(w, "Invalid role", http.StatusBadRequest)
+			return
+		}
+		inv.Role = babynames.Role(roleID)
+
+		free := false
+		for _, freeRole := range model.FreeRoles {
+			if freeRole.ID == inv.Role {
+				free = true
+			}
+		}
+		if !free {
+			http.Error(w, "That role has already joined the household", http.StatusBadRequest)
+			return
+		}
+	}
+
+	model.ExpiresAt = inv.ExpiresAt
+	model.GuestLink = inv.Guest
+	token := newInvitationToken(h.secret, inv)
+	model.Link = fmt.Sprintf("%s/invite/accept?token=%s", h.siteURL, url.QueryEscape(token))
+	renderTemplate(w, h.template, model)
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type deleteNoteHandler struct {
+	repo babynames.Repository
+}
+
+func newDeleteNoteHandler(repo babynames.Repository) *deleteNoteHandler {
+	return &deleteNoteHandler{
+		repo: repo,
+	}
+}
+
+func (h *deleteNoteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid note", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.DeleteNote(r.Context(), user.Role, id); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/notes", http.StatusSeeOther)
+}
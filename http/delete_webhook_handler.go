@@ -0,0 +1,33 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type deleteWebhookHandler struct {
+	repo babynames.Repository
+}
+
+func newDeleteWebhookHandler(repo babynames.Repository) *deleteWebhookHandler {
+	return &deleteWebhookHandler{
+		repo: repo,
+	}
+}
+
+func (h *deleteWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid webhook", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.DeleteWebhook(r.Context(), id); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/webhooks", http.StatusSeeOther)
+}
@@ -7,12 +7,14 @@ import (
 )
 
 type dislikeHandler struct {
-	repo babynames.Repository
+	repo  babynames.Repository
+	queue http.Handler
 }
 
-func newDislikeHandler(repo babynames.Repository) *dislikeHandler {
+func newDislikeHandler(repo babynames.Repository, queue http.Handler) *dislikeHandler {
 	return &dislikeHandler{
-		repo: repo,
+		repo:  repo,
+		queue: queue,
 	}
 }
 
@@ -20,11 +22,16 @@ func (h *dislikeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	user := getCurrentUser(r.Context())
 	name := r.FormValue("name")
 
-	_, err := h.repo.Dislike(r.Context(), user.Role, name)
+	var err error
+	if user.Guest {
+		err = h.repo.GuestVote(r.Context(), user.EmailAddress, name, babynames.GuestDislike)
+	} else {
+		_, err = h.repo.Dislike(r.Context(), user.Role, name)
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	nextInQueue(w, r, h.queue)
 }
@@ -1,14 +1,13 @@
 package http
 
 import (
-	"html/template"
 	"net/http"
 
 	"github.com/tanordheim/babyname-tinder"
 )
 
 type dislikedHandler struct {
-	template *template.Template
+	template *pageTemplate
 	repo     babynames.Repository
 }
 
@@ -23,7 +22,7 @@ func (h *dislikedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	user := getCurrentUser(r.Context())
 	dislikes, err := h.repo.GetDislikedNames(r.Context(), user.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 	renderTemplate(w, h.template, dislikes)
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// eventsKeepAliveInterval is how often a comment is sent on idle event streams, to keep proxies from closing them.
+const eventsKeepAliveInterval = 30 * time.Second
+
+type eventsHandler struct {
+	events       babynames.EventSubscriber
+	shuttingDown <-chan struct{}
+}
+
+type eventModel struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Who  string `json:"who"`
+}
+
+func newEventsHandler(events babynames.EventSubscriber, shuttingDown <-chan struct{}) *eventsHandler {
+	return &eventsHandler{
+		events:       events,
+		shuttingDown: shuttingDown,
+	}
+}
+
+// ServeHTTP streams the partner's matches and superlikes to the browser as server-sent events.
+func (h *eventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	if user.Guest {
+		// Tells the browser to stop reconnecting, as there are no events for guests
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// The stream is kept open for as long as the browser listens, well past the server's write timeout. Should
+	// lifting the deadline fail, the browser simply reconnects once the stream is cut.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	events, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.shuttingDown:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			// Only the partner of whoever caused a match or superlike needs to hear about it
+			if event.Type != babynames.MatchCreatedEvent && event.Type != babynames.SuperlikeEvent {
+				continue
+			}
+			if event.Role == user.Role {
+				continue
+			}
+
+			data, err := json.Marshal(&eventModel{
+				Type: string(event.Type),
+				Name: event.Name,
+				Who:  babynames.RoleName(event.Role),
+			})
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -21,9 +21,15 @@ func (h *exportDislikedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	user := getCurrentUser(r.Context())
 	dislikes, err := h.repo.GetDislikedNames(r.Context(), user.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
+	notes, err := h.repo.GetNotes(r.Context(), user.Role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	nameNotes := notesByName(notes, false)
 
 	w.Header().Add("Content-Type", "text/csv")
 	w.Header().Add("Content-Disposition", "attachment; filename=\"dislikes.csv\"")
@@ -31,9 +37,9 @@ func (h *exportDislikedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	csv := csv.NewWriter(w)
 	defer csv.Flush()
 
-	csv.Write([]string{"Name"})
+	csv.Write([]string{"Name", "Notes"})
 
 	for _, match := range dislikes {
-		csv.Write([]string{match.Name})
+		csv.Write([]string{match.Name, notesColumn(nameNotes[match.Name])})
 	}
 }
@@ -2,7 +2,10 @@ package http
 
 import (
 	"encoding/csv"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/tanordheim/babyname-tinder"
 )
@@ -21,9 +24,15 @@ func (h *exportMatchesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	user := getCurrentUser(r.Context())
 	matches, err := h.repo.GetMatches(r.Context(), user.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
+	notes, err := h.repo.GetNotes(r.Context(), user.Role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	nameNotes := notesByName(notes, false)
 
 	w.Header().Add("Content-Type", "text/csv")
 	w.Header().Add("Content-Disposition", "attachment; filename=\"matches.csv\"")
@@ -35,6 +44,9 @@ func (h *exportMatchesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		"Name",
 		"Dad Superliked",
 		"Mom Superliked",
+		"Score",
+		"Score Explanation",
+		"Notes",
 	})
 
 	for _, match := range matches {
@@ -53,6 +65,18 @@ func (h *exportMatchesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 			match.Name,
 			dadSuperliked,
 			momSuperliked,
+			strconv.Itoa(match.Score),
+			scoreExplanation(match.ScoreFactors()),
+			notesColumn(nameNotes[match.Name]),
 		})
 	}
 }
+
+// scoreExplanation formats the factors of a match score as a single CSV column.
+func scoreExplanation(factors []babynames.ScoreFactor) string {
+	res := make([]string, len(factors))
+	for idx, factor := range factors {
+		res[idx] = fmt.Sprintf("%s (%+d)", factor.Description, factor.Points)
+	}
+	return strings.Join(res, "; ")
+}
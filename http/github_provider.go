@@ -0,0 +1,52 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const githubEmailsURL = "https://api.github.com/user/emails"
+
+type githubProvider struct {
+	config *oauth2.Config
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// NewGitHubProvider creates an authentication provider signing users in through GitHub.
+func NewGitHubProvider(siteURL, clientID, clientSecret string) AuthProvider {
+	return &githubProvider{
+		config: newOAuthConfig(siteURL, clientID, clientSecret, github.Endpoint, "user:email"),
+	}
+}
+
+func (p *githubProvider) AuthCodeURL(state string) string {
+	return p.config.AuthCodeURL(state)
+}
+
+func (p *githubProvider) Authenticate(ctx context.Context, code string) (string, error) {
+	token, err := p.config.Exchange(ctx, code)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to exchange authorization code with GitHub")
+	}
+
+	// GitHub only exposes the profile e-mail if the user made it public, so look up the primary address instead
+	var emails []githubEmail
+	if err := getJSON(p.config.Client(ctx, token), githubEmailsURL, &emails); err != nil {
+		return "", err
+	}
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			return email.Email, nil
+		}
+	}
+	return "", fmt.Errorf("GitHub account has no verified primary e-mail address")
+}
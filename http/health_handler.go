@@ -0,0 +1,58 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// readinessTimeout bounds how long a readiness check may take, so probes get an answer before they time out.
+const readinessTimeout = 2 * time.Second
+
+// ReadinessChecker checks that the services the app depends on are ready to serve requests.
+type ReadinessChecker interface {
+	CheckReady(ctx context.Context) error
+}
+
+type healthHandler struct{}
+
+type readyHandler struct {
+	checker ReadinessChecker
+}
+
+func newHealthHandler() *healthHandler {
+	return &healthHandler{}
+}
+
+func newReadyHandler(checker ReadinessChecker) *readyHandler {
+	return &readyHandler{
+		checker: checker,
+	}
+}
+
+// ServeHTTP reports that the server is alive.
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// ServeHTTP reports whether the server is ready to serve requests. The reason it isn't is only logged, as the
+// endpoint isn't protected.
+func (h *readyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := h.checker.CheckReady(ctx); err != nil {
+		logrus.WithField("request_id", babynames.RequestID(r.Context())).WithError(err).Warn("Not ready")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "not ready")
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
@@ -1,23 +1,16 @@
 package http
 
 import (
+	"context"
 	"encoding/gob"
-	"fmt"
-	"html/template"
 	"math/rand"
 	"net/http"
-	"os"
-	"path"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
-	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/tanordheim/babyname-tinder"
-	"golang.org/x/oauth2"
-)
-
-var (
-	auth0Domain = os.Getenv("AUTH0_DOMAIN")
+	"github.com/tanordheim/babyname-tinder/metrics"
 )
 
 var (
@@ -53,53 +46,111 @@ func init() {
 	gob.Register(&user{})
 }
 
-// NewServer creates a new HTTP server.
+// NewServer creates a new HTTP server. If authProvider is nil, users sign in with local accounts instead, and
+// if mailer is set they can also sign in and reset their password through links sent by e-mail. Templates and
+// static files are built in, unless assetsDir is set to serve them from disk during development.
 func NewServer(
 	port int,
+	timeouts Timeouts,
+	snoozeLengths SnoozeLengths,
 	siteURL string,
 	cookieSecret string,
-	oauthClientID string,
-	oauthClientSecret string,
+	assetsDir string,
+	authProvider AuthProvider,
+	mailer babynames.Mailer,
+	events babynames.EventSubscriber,
 	repo babynames.Repository,
+	readiness ReadinessChecker,
 ) *Server {
 
+	if assetsDir != "" {
+		useAssetsDir(assetsDir)
+	}
 	sessionStore := sessions.NewCookieStore([]byte(cookieSecret))
+	shuttingDown := make(chan struct{})
 	router := mux.NewRouter()
-	oauthConfig := newOAuthConfig(siteURL, oauthClientID, oauthClientSecret)
+	router.Use(metrics.Middleware)
 
 	// Static content
-	cwd, err := os.Getwd()
-	if err != nil {
-		panic(errors.Wrap(err, "Unable to get working directory"))
-	}
-	router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir(path.Join(cwd, "static")))))
+	router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", staticHandler()))
+
+	// Health checks, left unauthenticated so they can be probed
+	router.Handle("/healthz", newHealthHandler()).Methods("GET")
+	router.Handle("/readyz", newReadyHandler(readiness)).Methods("GET")
 
 	// Authentication routes
-	router.Handle("/login", newLoginHandler(oauthConfig, sessionStore)).Methods("GET")
-	router.Handle("/callback", newCallbackHandler(oauthConfig, sessionStore)).Methods("GET")
+	if authProvider != nil {
+		router.Handle("/login", newLoginHandler(authProvider, sessionStore)).Methods("GET")
+		router.Handle("/callback", newCallbackHandler(authProvider, sessionStore, repo)).Methods("GET")
+	} else {
+		router.Handle("/login", newLocalLoginFormHandler(mailer != nil)).Methods("GET")
+		router.Handle("/login", newLocalLoginHandler(sessionStore, repo, mailer != nil)).Methods("POST")
+		router.Handle("/signup", newSignupFormHandler()).Methods("GET")
+		router.Handle("/signup", newSignupHandler(siteURL, sessionStore, repo, mailer)).Methods("POST")
+		if mailer != nil {
+			router.Handle("/login/magic", newMagicLinkHandler(siteURL, repo, mailer)).Methods("POST")
+			router.Handle("/login/magic", newMagicLinkLoginHandler(sessionStore, repo)).Methods("GET")
+			router.Handle("/password/reset", newPasswordResetFormHandler()).Methods("GET")
+			router.Handle("/password/reset", newPasswordResetHandler(siteURL, repo, mailer)).Methods("POST")
+			router.Handle("/password/reset/confirm", newPasswordResetConfirmFormHandler()).Methods("GET")
+			router.Handle("/password/reset/confirm", newPasswordResetConfirmHandler(sessionStore, repo)).Methods("POST")
+		}
+	}
 
 	// App routes
-	router.Handle("/", withAuth(sessionStore, newQueueHandler(repo))).Methods("GET")
-	router.Handle("/like", withAuth(sessionStore, newLikeHandler(repo))).Methods("POST")
-	router.Handle("/like/undo", withAuth(sessionStore, newUndoLikeHandler(repo))).Methods("POST")
-	router.Handle("/superlike", withAuth(sessionStore, newSuperlikeHandler(repo))).Methods("POST")
-	router.Handle("/dislike", withAuth(sessionStore, newDislikeHandler(repo))).Methods("POST")
-	router.Handle("/dislike/undo", withAuth(sessionStore, newUndoDislikeHandler(repo))).Methods("POST")
-	router.Handle("/liked", withAuth(sessionStore, newLikedHandler(repo))).Methods("GET")
-	router.Handle("/liked/export_csv", withAuth(sessionStore, newExportLikedHandler(repo))).Methods("GET")
-	router.Handle("/disliked", withAuth(sessionStore, newDislikedHandler(repo))).Methods("GET")
-	router.Handle("/disliked/export_csv", withAuth(sessionStore, newExportDislikedHandler(repo))).Methods("GET")
-	router.Handle("/matches", withAuth(sessionStore, newMatchesHandler(repo))).Methods("GET")
-	router.Handle("/matches/export_csv", withAuth(sessionStore, newExportMatchesHandler(repo))).Methods("GET")
-	router.Handle("/stats", withAuth(sessionStore, newStatsHandler(repo))).Methods("GET")
+	queue := newQueueHandler(repo, snoozeLengths)
+	router.Handle("/", withAuth(sessionStore, queue)).Methods("GET")
+	router.Handle("/next", withParentAuth(sessionStore, newNextNamesHandler(repo))).Methods("GET")
+	router.Handle("/swipes", withParentAuth(sessionStore, newSwipesHandler(repo))).Methods("POST")
+	router.Handle("/like", withAuth(sessionStore, newLikeHandler(repo, queue))).Methods("POST")
+	router.Handle("/like/undo", withParentAuth(sessionStore, newUndoLikeHandler(repo))).Methods("POST")
+	router.Handle("/rate", withParentAuth(sessionStore, newRateHandler(repo, queue))).Methods("POST")
+	router.Handle("/superlike", withAuth(sessionStore, newSuperlikeHandler(repo, queue))).Methods("POST")
+	router.Handle("/dislike", withAuth(sessionStore, newDislikeHandler(repo, queue))).Methods("POST")
+	router.Handle("/dislike/undo", withParentAuth(sessionStore, newUndoDislikeHandler(repo))).Methods("POST")
+	router.Handle("/snooze", withParentAuth(sessionStore, newSnoozeHandler(repo, queue, snoozeLengths))).Methods("POST")
+	router.Handle("/snooze/undo", withParentAuth(sessionStore, newUndoSnoozeHandler(repo))).Methods("POST")
+	router.Handle("/liked", withParentAuth(sessionStore, newLikedHandler(repo))).Methods("GET")
+	router.Handle("/tags", withParentAuth(sessionStore, newTagHandler(repo))).Methods("POST")
+	router.Handle("/tags/delete", withParentAuth(sessionStore, newUntagHandler(repo))).Methods("POST")
+	router.Handle("/liked/export_csv", withParentAuth(sessionStore, newExportLikedHandler(repo))).Methods("GET")
+	router.Handle("/disliked", withParentAuth(sessionStore, newDislikedHandler(repo))).Methods("GET")
+	router.Handle("/disliked/export_csv", withParentAuth(sessionStore, newExportDislikedHandler(repo))).Methods("GET")
+	router.Handle("/snoozed", withParentAuth(sessionStore, newSnoozedHandler(repo))).Methods("GET")
+	router.Handle("/matches", withParentAuth(sessionStore, newMatchesHandler(repo))).Methods("GET")
+	router.Handle("/matches/export_csv", withParentAuth(sessionStore, newExportMatchesHandler(repo))).Methods("GET")
+	router.Handle("/notes", withParentAuth(sessionStore, newNotesHandler(repo))).Methods("GET")
+	router.Handle("/notes", withParentAuth(sessionStore, newAddNoteHandler(repo))).Methods("POST")
+	router.Handle("/notes/delete", withParentAuth(sessionStore, newDeleteNoteHandler(repo))).Methods("POST")
+	router.Handle("/stats", withParentAuth(sessionStore, newStatsHandler(repo))).Methods("GET")
+	router.Handle("/agreement", withParentAuth(sessionStore, newAgreementHandler(repo))).Methods("GET")
+	router.Handle("/events", withAuth(sessionStore, newEventsHandler(events, shuttingDown))).Methods("GET")
+
+	// Settings routes
+	router.Handle("/settings", withParentAuth(sessionStore, newSettingsHandler(repo))).Methods("GET")
+	router.Handle("/settings", withParentAuth(sessionStore, newUpdateSettingsHandler(repo))).Methods("POST")
+	router.Handle("/webhooks", withParentAuth(sessionStore, newWebhooksHandler(repo))).Methods("GET")
+	router.Handle("/webhooks", withParentAuth(sessionStore, newAddWebhookHandler(repo))).Methods("POST")
+	router.Handle("/webhooks/delete", withParentAuth(sessionStore, newDeleteWebhookHandler(repo))).Methods("POST")
+
+	// Household routes
+	router.Handle("/invite", withParentAuth(sessionStore, newInviteHandler(repo))).Methods("GET")
+	router.Handle("/invite", withParentAuth(sessionStore, newCreateInvitationHandler(siteURL, []byte(cookieSecret), repo))).Methods("POST")
+	router.Handle("/invite/accept", newAcceptInvitationHandler([]byte(cookieSecret), sessionStore)).Methods("GET")
 
 	// Admin routes
-	router.Handle("/import", withAuth(sessionStore, newImportFormHandler())).Methods("GET")
-	router.Handle("/import", withAuth(sessionStore, newImportHandler(repo))).Methods("POST")
+	router.Handle("/import", withParentAuth(sessionStore, newImportFormHandler())).Methods("GET")
+	router.Handle("/import", withParentAuth(sessionStore, newImportHandler(repo))).Methods("POST")
+	router.Handle("/backup", withParentAuth(sessionStore, newBackupHandler(repo))).Methods("GET")
+	router.Handle("/restore", withParentAuth(sessionStore, newRestoreFormHandler())).Methods("GET")
+	router.Handle("/restore", withParentAuth(sessionStore, newRestoreHandler(repo))).Methods("POST")
 
 	return &Server{
-		port:   port,
-		router: router,
+		port:          port,
+		timeouts:      timeouts,
+		router:        router,
+		errorTemplate: parseTemplate("error"),
+		shuttingDown:  shuttingDown,
 	}
 
 }
@@ -108,7 +159,7 @@ func withAuth(session sessions.Store, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		sess, err := session.Get(r, "auth")
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			serverError(w, r, err)
 			return
 		}
 
@@ -121,40 +172,124 @@ func withAuth(session sessions.Store, next http.Handler) http.Handler {
 	})
 }
 
-func newOAuthConfig(siteURL, clientID, clientSecret string) *oauth2.Config {
-	return &oauth2.Config{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		RedirectURL:  fmt.Sprintf("%s/callback", siteURL),
-		Scopes:       []string{"openid", "profile", "email"},
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  fmt.Sprintf("https://%s/authorize", auth0Domain),
-			TokenURL: fmt.Sprintf("https://%s/oauth/token", auth0Domain),
-		},
-	}
+// withParentAuth only lets mom and dad through, keeping guest voters out.
+func withParentAuth(session sessions.Store, next http.Handler) http.Handler {
+	return withAuth(session, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if getCurrentUser(r.Context()).Guest {
+			http.Error(w, "This page is only available to the parents", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
 }
 
-func getRoleForEmail(email string) (babynames.Role, error) {
-	switch email {
-	case os.Getenv("DAD_EMAIL"):
-		return babynames.DadRole, nil
-	case os.Getenv("MOM_EMAIL"):
-		return babynames.MomRole, nil
-	default:
-		return babynames.MomRole, fmt.Errorf("unknown email address")
+// startSession stores the signed in user in the auth session and sends them to the front page. Users that are
+// not yet members of the household or guests are let in if they have a pending invitation.
+func startSession(w http.ResponseWriter, r *http.Request, session sessions.Store, repo babynames.Repository, emailAddress string) {
+	u, err := getUser(r.Context(), repo, emailAddress)
+	if err == babynames.ErrNotMember {
+		u, err = redeemInvitation(w, r, session, repo, emailAddress)
+	}
+	if err == babynames.ErrNotMember || err == babynames.ErrMemberExists {
+		http.Error(w, "Unauthorized user", http.StatusUnauthorized)
+		return
 	}
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	sess, err := session.Get(r, "auth")
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	sess.Values["user"] = u
+
+	if err := sess.Save(r, w); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
-func parseTemplate(name string) *template.Template {
-	template, err := template.ParseFiles("templates/layout.html", fmt.Sprintf("templates/%s.html", name))
+// getUser looks up an e-mail address among the household members and guests, returning babynames.ErrNotMember
+// if it's neither.
+func getUser(ctx context.Context, repo babynames.Repository, emailAddress string) (*user, error) {
+	role, err := repo.GetRoleForEmail(ctx, emailAddress)
+	if err == nil {
+		return newUser(emailAddress, role), nil
+	}
+	if err != babynames.ErrNotMember {
+		return nil, err
+	}
+
+	guest, err := repo.IsGuest(ctx, emailAddress)
 	if err != nil {
-		panic(errors.Wrap(err, fmt.Sprintf("Unable to parse template '%s'", name)))
+		return nil, err
+	}
+	if !guest {
+		return nil, babynames.ErrNotMember
+	}
+	return newGuest(emailAddress), nil
+}
+
+func redeemInvitation(w http.ResponseWriter, r *http.Request, session sessions.Store, repo babynames.Repository, emailAddress string) (*user, error) {
+	inv, ok := getPendingInvitation(r, session)
+	if !ok {
+		return nil, babynames.ErrNotMember
+	}
+
+	var u *user
+	if inv.Guest {
+		if err := repo.AddGuest(r.Context(), emailAddress); err != nil {
+			return nil, err
+		}
+		u = newGuest(emailAddress)
+	} else {
+		if err := repo.AddMember(r.Context(), emailAddress, inv.Role); err != nil {
+			return nil, err
+		}
+		u = newUser(emailAddress, inv.Role)
 	}
-	return template
+
+	clearInvitation(w, r, session)
+	return u, nil
+}
+
+func renderTemplate(w http.ResponseWriter, template *pageTemplate, data interface{}) {
+	if err := template.execute(w, "layout", data); err != nil {
+		logrus.WithError(err).WithField("template", template.name).Error("Unable to render template")
+	}
+}
+
+// wantsFragment checks if a request was made by the page's script to swap out the page content in place, in
+// which case only the content is rendered.
+func wantsFragment(r *http.Request) bool {
+	return r.Header.Get("X-Requested-With") == "XMLHttpRequest"
 }
 
-func renderTemplate(w http.ResponseWriter, template *template.Template, data interface{}) {
-	template.ExecuteTemplate(w, "layout", data)
+// renderPage renders a template, leaving out the layout if only a fragment of the page was requested.
+func renderPage(w http.ResponseWriter, r *http.Request, template *pageTemplate, data interface{}) {
+	w.Header().Set("Vary", "X-Requested-With")
+	if wantsFragment(r) {
+		if err := template.execute(w, "content", data); err != nil {
+			logrus.WithError(err).WithField("template", template.name).Error("Unable to render template")
+		}
+		return
+	}
+	renderTemplate(w, template, data)
+}
+
+// nextInQueue sends the user on to the next card in the queue after a swipe. Plain form posts are redirected to
+// the queue, while the page's script gets the next card right away to save a round trip.
+func nextInQueue(w http.ResponseWriter, r *http.Request, queue http.Handler) {
+	if wantsFragment(r) {
+		queue.ServeHTTP(w, r)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
 func getRandomImage() string {
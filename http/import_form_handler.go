@@ -1,12 +1,11 @@
 package http
 
 import (
-	"html/template"
 	"net/http"
 )
 
 type importFormHandler struct {
-	template *template.Template
+	template *pageTemplate
 }
 
 func newImportFormHandler() *importFormHandler {
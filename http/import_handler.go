@@ -1,7 +1,6 @@
 package http
 
 import (
-	"html/template"
 	"net/http"
 	"strings"
 
@@ -9,7 +8,7 @@ import (
 )
 
 type importHandler struct {
-	template *template.Template
+	template *pageTemplate
 	repo     babynames.Repository
 }
 
@@ -35,7 +34,7 @@ func (h *importHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	err := h.repo.ImportNames(r.Context(), importNames)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 
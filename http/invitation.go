@@ -0,0 +1,115 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// guestInvitation is the invitation kind used for guest voters, in place of a role.
+const guestInvitation = "guest"
+
+// invitation describes an invitation to join the household, either with a role or as a guest voter.
+type invitation struct {
+	Role      babynames.Role
+	Guest     bool
+	ExpiresAt time.Time
+}
+
+// newInvitationToken creates a signed token for an invitation.
+func newInvitationToken(secret []byte, inv invitation) string {
+	kind := strconv.Itoa(int(inv.Role))
+	if inv.Guest {
+		kind = guestInvitation
+	}
+	payload := fmt.Sprintf("%s.%d", kind, inv.ExpiresAt.Unix())
+	return fmt.Sprintf("%s.%s", base64.RawURLEncoding.EncodeToString([]byte(payload)), signInvitation(secret, payload))
+}
+
+// parseInvitationToken verifies the signature and expiry of an invitation token, returning the invitation.
+func parseInvitationToken(secret []byte, token string) (invitation, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return invitation{}, fmt.Errorf("Malformed invitation")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return invitation{}, errors.Wrap(err, "Malformed invitation")
+	}
+	if !hmac.Equal([]byte(signInvitation(secret, string(payload))), []byte(parts[1])) {
+		return invitation{}, fmt.Errorf("Invalid invitation signature")
+	}
+
+	fields := strings.SplitN(string(payload), ".", 2)
+	if len(fields) != 2 {
+		return invitation{}, fmt.Errorf("Malformed invitation")
+	}
+
+	inv := invitation{}
+	if fields[0] == guestInvitation {
+		inv.Guest = true
+	} else {
+		role, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return invitation{}, errors.Wrap(err, "Malformed invitation role")
+		}
+		inv.Role = babynames.Role(role)
+	}
+
+	expires, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return invitation{}, errors.Wrap(err, "Malformed invitation expiry")
+	}
+	inv.ExpiresAt = time.Unix(expires, 0)
+	if time.Now().After(inv.ExpiresAt) {
+		return invitation{}, fmt.Errorf("The invitation has expired")
+	}
+
+	return inv, nil
+}
+
+func signInvitation(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("invitation:"))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// getPendingInvitation returns an accepted but not yet redeemed invitation in the session, if any.
+func getPendingInvitation(r *http.Request, session sessions.Store) (invitation, bool) {
+	sess, err := session.Get(r, "invitation")
+	if err != nil {
+		return invitation{}, false
+	}
+	role, ok := sess.Values["role"].(int)
+	if !ok {
+		return invitation{}, false
+	}
+	guest, _ := sess.Values["guest"].(bool)
+	expires, ok := sess.Values["expires"].(int64)
+	if !ok || time.Now().After(time.Unix(expires, 0)) {
+		return invitation{}, false
+	}
+	return invitation{
+		Role:      babynames.Role(role),
+		Guest:     guest,
+		ExpiresAt: time.Unix(expires, 0),
+	}, true
+}
+
+// clearInvitation forgets the pending invitation, which is used up once it's redeemed.
+func clearInvitation(w http.ResponseWriter, r *http.Request, session sessions.Store) {
+	if sess, err := session.Get(r, "invitation"); err == nil {
+		sess.Options.MaxAge = -1
+		sess.Save(r, w)
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type inviteHandler struct {
+	template *pageTemplate
+	repo     babynames.Repository
+}
+
+type inviteModel struct {
+	Members   []*inviteMemberModel
+	Guests    []babynames.Guest
+	FreeRoles []*inviteRoleModel
+	Link      string
+	GuestLink bool
+	ExpiresAt time.Time
+}
+
+type inviteMemberModel struct {
+	Email    string
+	Role     string
+	JoinedAt time.Time
+}
+
+type inviteRoleModel struct {
+	ID   babynames.Role
+	Name string
+}
+
+func newInviteHandler(repo babynames.Repository) *inviteHandler {
+	return &inviteHandler{
+		template: parseTemplate("invite"),
+		repo:     repo,
+	}
+}
+
+func (h *inviteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	model, err := newInviteModel(r, h.repo)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	renderTemplate(w, h.template, model)
+}
+
+func newInviteModel(r *http.Request, repo babynames.Repository) (*inviteModel, error) {
+	members, err := repo.GetMembers(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	guests, err := repo.GetGuests(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	model := &inviteModel{
+		Guests: guests,
+	}
+	taken := map[babynames.Role]bool{}
+	for _, member := range members {
+		taken[member.Role] = true
+		model.Members = append(model.Members, &inviteMemberModel{
+			Email:    member.Email,
+			Role:     babynames.RoleName(member.Role),
+			JoinedAt: member.JoinedAt,
+		})
+	}
+	for _, role := range babynames.Roles {
+		if !taken[role] {
+			model.FreeRoles = append(model.FreeRoles, &inviteRoleModel{
+				ID:   role,
+				Name: babynames.RoleName(role),
+			})
+		}
+	}
+	return model, nil
+}
@@ -7,12 +7,14 @@ import (
 )
 
 type likeHandler struct {
-	repo babynames.Repository
+	repo  babynames.Repository
+	queue http.Handler
 }
 
-func newLikeHandler(repo babynames.Repository) *likeHandler {
+func newLikeHandler(repo babynames.Repository, queue http.Handler) *likeHandler {
 	return &likeHandler{
-		repo: repo,
+		repo:  repo,
+		queue: queue,
 	}
 }
 
@@ -20,11 +22,16 @@ func (h *likeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	user := getCurrentUser(r.Context())
 	name := r.FormValue("name")
 
-	err := h.repo.Like(r.Context(), user.Role, name)
+	var err error
+	if user.Guest {
+		err = h.repo.GuestVote(r.Context(), user.EmailAddress, name, babynames.GuestLike)
+	} else {
+		err = h.repo.Like(r.Context(), user.Role, name)
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	nextInQueue(w, r, h.queue)
 }
@@ -1,17 +1,31 @@
 package http
 
 import (
-	"html/template"
 	"net/http"
+	"time"
 
 	"github.com/tanordheim/babyname-tinder"
 )
 
 type likedHandler struct {
-	template *template.Template
+	template *pageTemplate
 	repo     babynames.Repository
 }
 
+type likedModel struct {
+	Name       string
+	Superliked bool
+	Rating     int
+	LikedAt    time.Time
+	Guests     babynames.GuestOpinion
+	Tags       []string
+}
+
+type likedPageModel struct {
+	Filter tagFilterModel
+	Names  []*likedModel
+}
+
 func newLikedHandler(repo babynames.Repository) *likedHandler {
 	return &likedHandler{
 		template: parseTemplate("liked"),
@@ -23,8 +37,38 @@ func (h *likedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	user := getCurrentUser(r.Context())
 	likes, err := h.repo.GetLikedNames(r.Context(), user.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
+		return
+	}
+	opinions, err := h.repo.GetGuestOpinions(r.Context())
+	if err != nil {
+		serverError(w, r, err)
 		return
 	}
-	renderTemplate(w, h.template, likes)
+
+	tags, err := h.repo.GetTags(r.Context(), user.Role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	filter := newTagFilter("/liked", r.URL.Query().Get("tag"), tags)
+
+	res := []*likedModel{}
+	for _, like := range likes {
+		if !filter.matches(tags[like.Name]) {
+			continue
+		}
+		res = append(res, &likedModel{
+			Name:       like.Name,
+			Superliked: like.Superliked,
+			Rating:     like.Rating,
+			LikedAt:    like.LikedAt,
+			Guests:     opinions[like.Name],
+			Tags:       tags[like.Name],
+		})
+	}
+	renderTemplate(w, h.template, &likedPageModel{
+		Filter: filter,
+		Names:  res,
+	})
 }
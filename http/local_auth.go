@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+const (
+	magicLinkTTL     = 15 * time.Minute
+	passwordResetTTL = time.Hour
+)
+
+func validatePassword(password, confirmation string) string {
+	if babynames.ValidatePassword(password) == babynames.ErrPasswordTooShort {
+		return fmt.Sprintf("The password must be at least %d characters long.", babynames.MinPasswordLength)
+	}
+	if password != confirmation {
+		return "The passwords do not match."
+	}
+	return ""
+}
+
+func hashLoginToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sendLoginToken issues a one-time login token for a household member and e-mails them a link containing it.
+// Unknown e-mail addresses are silently ignored to avoid revealing who is a member of the household.
+func sendLoginToken(
+	ctx context.Context,
+	repo babynames.Repository,
+	mailer babynames.Mailer,
+	emailAddress string,
+	purpose babynames.LoginTokenPurpose,
+	ttl time.Duration,
+	link string,
+	subject string,
+	body string,
+) error {
+	if _, err := getUser(ctx, repo, emailAddress); err != nil {
+		if err == babynames.ErrNotMember {
+			return nil
+		}
+		return err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return errors.Wrap(err, "Unable to generate login token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := repo.CreateLoginToken(ctx, emailAddress, purpose, hashLoginToken(token), time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	return mailer.Send(ctx, babynames.Message{
+		To:      emailAddress,
+		Subject: subject,
+		Body:    fmt.Sprintf("%s\n\n%s?token=%s\n\nThe link expires in %v.\n", body, link, url.QueryEscape(token), ttl),
+	})
+}
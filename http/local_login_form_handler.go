@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http"
+)
+
+type localLoginFormHandler struct {
+	template   *pageTemplate
+	emailLogin bool
+}
+
+type localLoginModel struct {
+	Email      string
+	Error      string
+	EmailLogin bool
+}
+
+func newLocalLoginFormHandler(emailLogin bool) *localLoginFormHandler {
+	return &localLoginFormHandler{
+		template:   parseTemplate("login"),
+		emailLogin: emailLogin,
+	}
+}
+
+func (h *localLoginFormHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, h.template, &localLoginModel{EmailLogin: h.emailLogin})
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/sessions"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type localLoginHandler struct {
+	template   *pageTemplate
+	session    sessions.Store
+	repo       babynames.Repository
+	emailLogin bool
+}
+
+func newLocalLoginHandler(session sessions.Store, repo babynames.Repository, emailLogin bool) *localLoginHandler {
+	return &localLoginHandler{
+		template:   parseTemplate("login"),
+		session:    session,
+		repo:       repo,
+		emailLogin: emailLogin,
+	}
+}
+
+func (h *localLoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	emailAddress := strings.TrimSpace(r.FormValue("email"))
+	password := r.FormValue("password")
+
+	hash, err := h.repo.GetPasswordHash(r.Context(), emailAddress)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	if !babynames.CheckPassword(hash, password) {
+		w.WriteHeader(http.StatusUnauthorized)
+		renderTemplate(w, h.template, &localLoginModel{
+			Email:      emailAddress,
+			Error:      "Invalid e-mail address or password.",
+			EmailLogin: h.emailLogin,
+		})
+		return
+	}
+
+	startSession(w, r, h.session, h.repo, emailAddress)
+}
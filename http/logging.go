@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type errorModel struct {
+	RequestID string
+}
+
+// withRequestLogging gives every request an ID, passed on through the request context and the X-Request-ID
+// response header, and logs the request once it has been served. The error page shown by serverError is passed
+// on through the request context as well.
+func withRequestLogging(errorTemplate *pageTemplate, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := newRequestID()
+		w.Header().Set("X-Request-ID", requestID)
+
+		ctx := babynames.WithRequestID(r.Context(), requestID)
+		ctx = context.WithValue(ctx, errorTemplateContextKey, errorTemplate)
+
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		logrus.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"status":     recorder.status,
+			"size":       recorder.size,
+			"duration":   time.Since(start).Seconds(),
+			"remote":     r.RemoteAddr,
+			"user_agent": r.UserAgent(),
+		}).Info("Request served")
+	})
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
+
+// serverError logs the full error of a failed request, and shows the user an error page with the request ID to
+// look it up by instead of the error itself.
+func serverError(w http.ResponseWriter, r *http.Request, err error) {
+	requestID := babynames.RequestID(r.Context())
+	logrus.WithField("request_id", requestID).WithError(err).Error("Request failed")
+
+	errorTemplate, ok := r.Context().Value(errorTemplateContextKey).(*pageTemplate)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Something went wrong (request %s)", requestID), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	renderTemplate(w, errorTemplate, &errorModel{RequestID: requestID})
+}
+
+// responseRecorder captures the status code and size of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.size += n
+	return n, err
+}
+
+// Unwrap exposes the underlying response writer to http.ResponseController.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Flush passes flushes through, so streamed responses like the event stream keep working.
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -3,22 +3,20 @@ package http
 import (
 	"crypto/rand"
 	"encoding/base64"
-	"fmt"
 	"net/http"
 
 	"github.com/gorilla/sessions"
-	"golang.org/x/oauth2"
 )
 
 type loginHandler struct {
-	config  *oauth2.Config
-	session sessions.Store
+	provider AuthProvider
+	session  sessions.Store
 }
 
-func newLoginHandler(config *oauth2.Config, session sessions.Store) *loginHandler {
+func newLoginHandler(provider AuthProvider, session sessions.Store) *loginHandler {
 	return &loginHandler{
-		config:  config,
-		session: session,
+		provider: provider,
+		session:  session,
 	}
 }
 
@@ -30,17 +28,15 @@ func (h *loginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	session, err := h.session.Get(r, "state")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 	session.Values["state"] = state
 	if err := session.Save(r, w); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 
 	// Start the auth flow
-	aud := oauth2.SetAuthURLParam("audience", fmt.Sprintf("https://%s/userinfo", auth0Domain))
-	url := h.config.AuthCodeURL(state, aud)
-	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
+	http.Redirect(w, r, h.provider.AuthCodeURL(state), http.StatusTemporaryRedirect)
 }
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type magicLinkHandler struct {
+	template *pageTemplate
+	siteURL  string
+	repo     babynames.Repository
+	mailer   babynames.Mailer
+}
+
+func newMagicLinkHandler(siteURL string, repo babynames.Repository, mailer babynames.Mailer) *magicLinkHandler {
+	return &magicLinkHandler{
+		template: parseTemplate("login_email_sent"),
+		siteURL:  siteURL,
+		repo:     repo,
+		mailer:   mailer,
+	}
+}
+
+func (h *magicLinkHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	emailAddress := strings.TrimSpace(r.FormValue("email"))
+
+	err := sendLoginToken(
+		r.Context(),
+		h.repo,
+		h.mailer,
+		emailAddress,
+		babynames.MagicLinkToken,
+		magicLinkTTL,
+		fmt.Sprintf("%s/login/magic", h.siteURL),
+		"Your Babynames login link",
+		"Follow the link below to log in to Babynames.",
+	)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	renderTemplate(w, h.template, emailAddress)
+}
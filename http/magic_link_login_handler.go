@@ -0,0 +1,36 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/sessions"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type magicLinkLoginHandler struct {
+	session sessions.Store
+	repo    babynames.Repository
+}
+
+func newMagicLinkLoginHandler(session sessions.Store, repo babynames.Repository) *magicLinkLoginHandler {
+	return &magicLinkLoginHandler{
+		session: session,
+		repo:    repo,
+	}
+}
+
+func (h *magicLinkLoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+
+	emailAddress, err := h.repo.ConsumeLoginToken(r.Context(), babynames.MagicLinkToken, hashLoginToken(token))
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	if emailAddress == "" {
+		http.Error(w, "Invalid or expired login link", http.StatusUnauthorized)
+		return
+	}
+
+	startSession(w, r, h.session, h.repo, emailAddress)
+}
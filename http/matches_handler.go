@@ -1,7 +1,6 @@
 package http
 
 import (
-	"html/template"
 	"net/http"
 	"time"
 
@@ -9,7 +8,7 @@ import (
 )
 
 type matchesHandler struct {
-	template *template.Template
+	template *pageTemplate
 	repo     babynames.Repository
 }
 
@@ -18,6 +17,16 @@ type matchesModel struct {
 	MatchedAt     time.Time
 	MomSuperliked bool
 	DadSuperliked bool
+	Score         int
+	ScoreFactors  []babynames.ScoreFactor
+	Guests        babynames.GuestOpinion
+	Notes         []babynames.Note
+	Tags          []string
+}
+
+type matchesPageModel struct {
+	Filter  tagFilterModel
+	Matches []*matchesModel
 }
 
 func newMatchesHandler(repo babynames.Repository) *matchesHandler {
@@ -31,12 +40,33 @@ func (h *matchesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	user := getCurrentUser(r.Context())
 	matches, err := h.repo.GetMatches(r.Context(), user.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
+		return
+	}
+	opinions, err := h.repo.GetGuestOpinions(r.Context())
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	notes, err := h.repo.GetNotes(r.Context(), user.Role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	sharedNotes := notesByName(notes, true)
+	tags, err := h.repo.GetTags(r.Context(), user.Role)
+	if err != nil {
+		serverError(w, r, err)
 		return
 	}
+	filter := newTagFilter("/matches", r.URL.Query().Get("tag"), tags)
+
+	res := []*matchesModel{}
+	for _, match := range matches {
+		if !filter.matches(tags[match.Name]) {
+			continue
+		}
 
-	res := make([]*matchesModel, len(matches))
-	for idx, match := range matches {
 		matchedAt := time.Time{}
 		dadSuperliked := false
 		momSuperliked := false
@@ -51,12 +81,20 @@ func (h *matchesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				momSuperliked = true
 			}
 		}
-		res[idx] = &matchesModel{
+		res = append(res, &matchesModel{
 			Name:          match.Name,
 			MatchedAt:     matchedAt,
 			DadSuperliked: dadSuperliked,
 			MomSuperliked: momSuperliked,
-		}
+			Score:         match.Score,
+			ScoreFactors:  match.ScoreFactors(),
+			Guests:        opinions[match.Name],
+			Notes:         sharedNotes[match.Name],
+			Tags:          tags[match.Name],
+		})
 	}
-	renderTemplate(w, h.template, res)
+	renderTemplate(w, h.template, &matchesPageModel{
+		Filter:  filter,
+		Matches: res,
+	})
 }
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+const (
+	// defaultPrefetchCount is the number of upcoming names returned when the client doesn't ask for a count.
+	defaultPrefetchCount = 5
+
+	// maxPrefetchCount caps the number of upcoming names a client can prefetch at once.
+	maxPrefetchCount = 20
+)
+
+type nextNamesHandler struct {
+	repo babynames.Repository
+}
+
+type queuedNameModel struct {
+	Name     string `json:"name"`
+	Dislikes int    `json:"dislikes"`
+}
+
+func newNextNamesHandler(repo babynames.Repository) *nextNamesHandler {
+	return &nextNamesHandler{
+		repo: repo,
+	}
+}
+
+// ServeHTTP returns the upcoming names in the role's queue as JSON, so clients can prefetch the next cards.
+func (h *nextNamesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	count := defaultPrefetchCount
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid count", http.StatusBadRequest)
+			return
+		}
+		count = n
+	}
+	if count > maxPrefetchCount {
+		count = maxPrefetchCount
+	}
+
+	user := getCurrentUser(r.Context())
+	names, err := h.repo.GetNextNames(r.Context(), user.Role, count)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	res := make([]queuedNameModel, 0, len(names))
+	for _, name := range names {
+		res = append(res, queuedNameModel{
+			Name:     name.Name,
+			Dislikes: name.Dislikes,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
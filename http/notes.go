@@ -0,0 +1,29 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// notesByName groups notes by the name they're attached to, optionally leaving out private notes.
+func notesByName(notes []babynames.Note, sharedOnly bool) map[string][]babynames.Note {
+	res := map[string][]babynames.Note{}
+	for _, note := range notes {
+		if sharedOnly && !note.Shared {
+			continue
+		}
+		res[note.Name] = append(res[note.Name], note)
+	}
+	return res
+}
+
+// notesColumn formats notes as a single CSV column, prefixing each note with who wrote it.
+func notesColumn(notes []babynames.Note) string {
+	res := make([]string, len(notes))
+	for idx, note := range notes {
+		res[idx] = fmt.Sprintf("%s: %s", babynames.RoleName(note.Role), note.Text)
+	}
+	return strings.Join(res, "; ")
+}
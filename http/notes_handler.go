@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type notesHandler struct {
+	template *pageTemplate
+	repo     babynames.Repository
+}
+
+type notesModel struct {
+	Name  string
+	Role  babynames.Role
+	Notes []babynames.Note
+}
+
+func newNotesHandler(repo babynames.Repository) *notesHandler {
+	return &notesHandler{
+		template: parseTemplate("notes"),
+		repo:     repo,
+	}
+}
+
+func (h *notesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	notes, err := h.repo.GetNotes(r.Context(), user.Role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	renderTemplate(w, h.template, &notesModel{
+		Name:  r.URL.Query().Get("name"),
+		Role:  user.Role,
+		Notes: notes,
+	})
+}
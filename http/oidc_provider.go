@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+type oidcProvider struct {
+	userInfoURL string
+	config      *oauth2.Config
+}
+
+type oidcDiscovery struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+type oidcUserInfo struct {
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"`
+}
+
+// NewOIDCProvider creates an authentication provider signing users in through a generic OpenID Connect
+// provider, discovering its endpoints from the issuer URL.
+func NewOIDCProvider(ctx context.Context, issuerURL, siteURL, clientID, clientSecret string) (AuthProvider, error) {
+	discoveryURL := fmt.Sprintf("%s/.well-known/openid-configuration", strings.TrimSuffix(issuerURL, "/"))
+
+	var discovery oidcDiscovery
+	if err := getJSON(oauth2.NewClient(ctx, nil), discoveryURL, &discovery); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Unable to discover OpenID Connect configuration for '%s'", issuerURL))
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserInfoEndpoint == "" {
+		return nil, fmt.Errorf("OpenID Connect configuration for '%s' is missing required endpoints", issuerURL)
+	}
+
+	endpoint := oauth2.Endpoint{
+		AuthURL:  discovery.AuthorizationEndpoint,
+		TokenURL: discovery.TokenEndpoint,
+	}
+	return &oidcProvider{
+		userInfoURL: discovery.UserInfoEndpoint,
+		config:      newOAuthConfig(siteURL, clientID, clientSecret, endpoint, "openid", "profile", "email"),
+	}, nil
+}
+
+// NewGoogleProvider creates an authentication provider signing users in through Google.
+func NewGoogleProvider(ctx context.Context, siteURL, clientID, clientSecret string) (AuthProvider, error) {
+	return NewOIDCProvider(ctx, "https://accounts.google.com", siteURL, clientID, clientSecret)
+}
+
+func (p *oidcProvider) AuthCodeURL(state string) string {
+	return p.config.AuthCodeURL(state)
+}
+
+func (p *oidcProvider) Authenticate(ctx context.Context, code string) (string, error) {
+	token, err := p.config.Exchange(ctx, code)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to exchange authorization code with OpenID Connect provider")
+	}
+
+	var info oidcUserInfo
+	if err := getJSON(p.config.Client(ctx, token), p.userInfoURL, &info); err != nil {
+		return "", err
+	}
+
+	if info.Email == "" {
+		return "", fmt.Errorf("OpenID Connect user info has no e-mail address")
+	}
+	if info.EmailVerified != nil && !*info.EmailVerified {
+		return "", fmt.Errorf("E-mail address '%s' has not been verified", info.Email)
+	}
+	return info.Email, nil
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// oidcTestRepository knows mom's e-mail address, which is all the callback needs to start a session.
+type oidcTestRepository struct {
+	babynames.Repository
+}
+
+func (r *oidcTestRepository) GetRoleForEmail(ctx context.Context, email string) (babynames.Role, error) {
+	if email == "mom@example.com" {
+		return babynames.MomRole, nil
+	}
+	return 0, babynames.ErrNotMember
+}
+
+// newOIDCTestIssuer serves the discovery document, token endpoint and user info endpoint of an OpenID Connect
+// provider that signs in whoever presents the code "good-code" with the given user info.
+func newOIDCTestIssuer(t *testing.T, userInfo map[string]interface{}) *httptest.Server {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "authorization_code" || r.FormValue("code") != "good-code" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		if r.FormValue("redirect_uri") != "https://babynames.example.com/callback" {
+			t.Errorf("Unexpected redirect URI '%s'", r.FormValue("redirect_uri"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "good-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(userInfo)
+	})
+	return srv
+}
+
+func newTestOIDCProvider(t *testing.T, userInfo map[string]interface{}) (AuthProvider, *httptest.Server) {
+	issuer := newOIDCTestIssuer(t, userInfo)
+	provider, err := NewOIDCProvider(context.Background(), issuer.URL, "https://babynames.example.com", "client-id", "client-secret")
+	if err != nil {
+		t.Fatalf("Unable to create OpenID Connect provider: %v", err)
+	}
+	return provider, issuer
+}
+
+func TestOIDCProviderDiscoversAuthorizationEndpoint(t *testing.T) {
+	provider, issuer := newTestOIDCProvider(t, nil)
+
+	authURL, err := url.Parse(provider.AuthCodeURL("some-state"))
+	if err != nil {
+		t.Fatalf("Unable to parse authorization URL: %v", err)
+	}
+	if got := authURL.Scheme + "://" + authURL.Host + authURL.Path; got != issuer.URL+"/authorize" {
+		t.Errorf("Expected authorization URL at '%s/authorize', got '%s'", issuer.URL, got)
+	}
+	query := authURL.Query()
+	if query.Get("state") != "some-state" || query.Get("client_id") != "client-id" {
+		t.Errorf("Unexpected authorization URL query '%s'", authURL.RawQuery)
+	}
+	if scopes := strings.Fields(query.Get("scope")); len(scopes) != 3 || scopes[0] != "openid" {
+		t.Errorf("Unexpected scopes '%s'", query.Get("scope"))
+	}
+}
+
+func TestOIDCProviderRequiresEndpoints(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"authorization_endpoint": "https://example.com/authorize"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	if _, err := NewOIDCProvider(context.Background(), srv.URL, "https://babynames.example.com", "client-id", "client-secret"); err == nil {
+		t.Error("Expected an error for a discovery document without token and user info endpoints")
+	}
+}
+
+func TestOIDCProviderRejectsUnverifiedEmail(t *testing.T) {
+	provider, _ := newTestOIDCProvider(t, map[string]interface{}{"email": "mom@example.com", "email_verified": false})
+
+	if _, err := provider.Authenticate(context.Background(), "good-code"); err == nil {
+		t.Error("Expected an error for an unverified e-mail address")
+	}
+}
+
+func TestOIDCProviderRejectsBadCode(t *testing.T) {
+	provider, _ := newTestOIDCProvider(t, map[string]interface{}{"email": "mom@example.com", "email_verified": true})
+
+	if _, err := provider.Authenticate(context.Background(), "bad-code"); err == nil {
+		t.Error("Expected an error for an authorization code the provider doesn't accept")
+	}
+}
+
+func TestOIDCCallbackStartsSession(t *testing.T) {
+	provider, _ := newTestOIDCProvider(t, map[string]interface{}{"email": "mom@example.com", "email_verified": true})
+	store := sessions.NewCookieStore([]byte("test-cookie-secret"))
+
+	// The login handler stores the state the callback is checked against
+	loginReq := httptest.NewRequest("GET", "/login", nil)
+	loginRes := httptest.NewRecorder()
+	state, err := store.Get(loginReq, "state")
+	if err != nil {
+		t.Fatalf("Unable to get state session: %v", err)
+	}
+	state.Values["state"] = "some-state"
+	if err := state.Save(loginReq, loginRes); err != nil {
+		t.Fatalf("Unable to save state session: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/callback?state=some-state&code=good-code", nil)
+	for _, cookie := range loginRes.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	res := httptest.NewRecorder()
+	newCallbackHandler(provider, store, &oidcTestRepository{}).ServeHTTP(res, req)
+
+	if res.Code != http.StatusSeeOther || res.Header().Get("Location") != "/" {
+		t.Fatalf("Expected a redirect to '/', got status %d to '%s'", res.Code, res.Header().Get("Location"))
+	}
+
+	// The session cookie set by the callback identifies mom
+	sessReq := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range res.Result().Cookies() {
+		sessReq.AddCookie(cookie)
+	}
+	sess, err := store.Get(sessReq, "auth")
+	if err != nil {
+		t.Fatalf("Unable to get auth session: %v", err)
+	}
+	u, ok := sess.Values["user"].(*user)
+	if !ok {
+		t.Fatal("Expected the auth session to hold a user")
+	}
+	if u.EmailAddress != "mom@example.com" || u.Role != babynames.MomRole || u.Guest {
+		t.Errorf("Unexpected user in session: %+v", u)
+	}
+}
+
+func TestOIDCCallbackRejectsInvalidState(t *testing.T) {
+	provider, _ := newTestOIDCProvider(t, map[string]interface{}{"email": "mom@example.com", "email_verified": true})
+	store := sessions.NewCookieStore([]byte("test-cookie-secret"))
+
+	req := httptest.NewRequest("GET", "/callback?state=forged-state&code=good-code", nil)
+	res := httptest.NewRecorder()
+	newCallbackHandler(provider, store, &oidcTestRepository{}).ServeHTTP(res, req)
+
+	if res.Code == http.StatusSeeOther {
+		t.Error("Expected the callback to refuse a state it didn't hand out")
+	}
+	for _, cookie := range res.Result().Cookies() {
+		if cookie.Name == "auth" {
+			t.Error("Expected no session to be started for an invalid state")
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+)
+
+type passwordResetConfirmFormHandler struct {
+	template *pageTemplate
+}
+
+type passwordResetConfirmModel struct {
+	Token string
+	Error string
+}
+
+func newPasswordResetConfirmFormHandler() *passwordResetConfirmFormHandler {
+	return &passwordResetConfirmFormHandler{
+		template: parseTemplate("password_reset_confirm"),
+	}
+}
+
+func (h *passwordResetConfirmFormHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, h.template, &passwordResetConfirmModel{
+		Token: r.URL.Query().Get("token"),
+	})
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/sessions"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type passwordResetConfirmHandler struct {
+	template *pageTemplate
+	session  sessions.Store
+	repo     babynames.Repository
+}
+
+func newPasswordResetConfirmHandler(session sessions.Store, repo babynames.Repository) *passwordResetConfirmHandler {
+	return &passwordResetConfirmHandler{
+		template: parseTemplate("password_reset_confirm"),
+		session:  session,
+		repo:     repo,
+	}
+}
+
+func (h *passwordResetConfirmHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	password := r.FormValue("password")
+
+	// Validate the password before using up the token, so the user can try again
+	if msg := validatePassword(password, r.FormValue("password_confirm")); msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		renderTemplate(w, h.template, &passwordResetConfirmModel{
+			Token: token,
+			Error: msg,
+		})
+		return
+	}
+
+	emailAddress, err := h.repo.ConsumeLoginToken(r.Context(), babynames.PasswordResetToken, hashLoginToken(token))
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	if emailAddress == "" {
+		http.Error(w, "Invalid or expired password reset link", http.StatusUnauthorized)
+		return
+	}
+
+	hash, err := babynames.HashPassword(password)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	if err := h.repo.SetPasswordHash(r.Context(), emailAddress, hash); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	startSession(w, r, h.session, h.repo, emailAddress)
+}
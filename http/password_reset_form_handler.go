@@ -0,0 +1,19 @@
+package http
+
+import (
+	"net/http"
+)
+
+type passwordResetFormHandler struct {
+	template *pageTemplate
+}
+
+func newPasswordResetFormHandler() *passwordResetFormHandler {
+	return &passwordResetFormHandler{
+		template: parseTemplate("password_reset"),
+	}
+}
+
+func (h *passwordResetFormHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, h.template, nil)
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type passwordResetHandler struct {
+	template *pageTemplate
+	siteURL  string
+	repo     babynames.Repository
+	mailer   babynames.Mailer
+}
+
+func newPasswordResetHandler(siteURL string, repo babynames.Repository, mailer babynames.Mailer) *passwordResetHandler {
+	return &passwordResetHandler{
+		template: parseTemplate("login_email_sent"),
+		siteURL:  siteURL,
+		repo:     repo,
+		mailer:   mailer,
+	}
+}
+
+func (h *passwordResetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	emailAddress := strings.TrimSpace(r.FormValue("email"))
+
+	err := sendLoginToken(
+		r.Context(),
+		h.repo,
+		h.mailer,
+		emailAddress,
+		babynames.PasswordResetToken,
+		passwordResetTTL,
+		fmt.Sprintf("%s/password/reset/confirm", h.siteURL),
+		"Reset your Babynames password",
+		"Follow the link below to choose a new password for Babynames. If you did not ask for this, you can ignore this e-mail.",
+	)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	renderTemplate(w, h.template, emailAddress)
+}
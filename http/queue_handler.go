@@ -1,23 +1,25 @@
 package http
 
 import (
-	"html/template"
+	"fmt"
 	"net/http"
 
 	"github.com/tanordheim/babyname-tinder"
 )
 
 type queueHandler struct {
-	nameTemplate      *template.Template
-	matchTemplate     *template.Template
-	superlikeTemplate *template.Template
-	emptyTemplate     *template.Template
+	nameTemplate      *pageTemplate
+	matchTemplate     *pageTemplate
+	superlikeTemplate *pageTemplate
+	emptyTemplate     *pageTemplate
+	snoozeLengths     SnoozeLengths
 	repo              babynames.Repository
 }
 
 type matchModel struct {
 	Name  string
 	Image string
+	Notes []babynames.Note
 }
 
 type superlikeModel struct {
@@ -31,36 +33,51 @@ type nameModel struct {
 	Image              string
 	ProgressPercentage int
 	DislikedCount      int
+	CanSnooze          bool
+	SnoozeSwipes       int
+	SnoozeOptions      []snoozeOption
+	RatingMode         bool
+	Ratings            []int
 }
 
-func newQueueHandler(repo babynames.Repository) *queueHandler {
+type snoozeOption struct {
+	Days  int
+	Label string
+}
+
+func newQueueHandler(repo babynames.Repository, snoozeLengths SnoozeLengths) *queueHandler {
 	return &queueHandler{
 		nameTemplate:      parseTemplate("queue_name"),
 		matchTemplate:     parseTemplate("queue_match"),
 		superlikeTemplate: parseTemplate("queue_superlike"),
 		emptyTemplate:     parseTemplate("queue_empty"),
+		snoozeLengths:     snoozeLengths,
 		repo:              repo,
 	}
 }
 
 func (h *queueHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	user := getCurrentUser(r.Context())
+	if user.Guest {
+		h.serveGuest(w, r, user)
+		return
+	}
 
 	// If we have a match, show that
 	match, err := h.repo.GetAndAcknowledgeUnseenMatch(r.Context(), user.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 	if match != "" {
-		h.renderMatch(w, r, match)
+		h.renderMatch(w, r, match, user.Role)
 		return
 	}
 
 	// If there's a pending superlike, show that
 	like, err := h.repo.GetPendingSuperlike(r.Context(), user.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 	if like != "" {
@@ -71,7 +88,7 @@ func (h *queueHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// If there's a pending name in the queue, show that
 	name, dislikes, err := h.repo.GetNextName(r.Context(), user.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 	if name != "" {
@@ -80,15 +97,42 @@ func (h *queueHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Show the "out of names" message
-	renderTemplate(w, h.emptyTemplate, nil)
+	renderPage(w, r, h.emptyTemplate, nil)
 }
 
-func (h *queueHandler) renderMatch(w http.ResponseWriter, r *http.Request, name string) {
+// serveGuest shows the next name for a guest voter. Guests never see matches or superlikes, as their votes are
+// advisory only.
+func (h *queueHandler) serveGuest(w http.ResponseWriter, r *http.Request, user *user) {
+	name, err := h.repo.GetNextGuestName(r.Context(), user.EmailAddress)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	if name == "" {
+		renderPage(w, r, h.emptyTemplate, nil)
+		return
+	}
+
+	model := &nameModel{
+		Name:  name,
+		Image: getRandomImage(),
+	}
+	renderPage(w, r, h.nameTemplate, model)
+}
+
+func (h *queueHandler) renderMatch(w http.ResponseWriter, r *http.Request, name string, role babynames.Role) {
+	notes, err := h.repo.GetNotes(r.Context(), role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
 	model := &matchModel{
 		Name:  name,
 		Image: getRandomImage(),
+		Notes: notesByName(notes, true)[name],
 	}
-	renderTemplate(w, h.matchTemplate, model)
+	renderPage(w, r, h.matchTemplate, model)
 }
 
 func (h *queueHandler) renderSuperlike(w http.ResponseWriter, r *http.Request, name string, role babynames.Role) {
@@ -97,22 +141,51 @@ func (h *queueHandler) renderSuperlike(w http.ResponseWriter, r *http.Request, n
 		Name:  name,
 		Image: getRandomImage(),
 	}
-	renderTemplate(w, h.superlikeTemplate, model)
+	renderPage(w, r, h.superlikeTemplate, model)
 }
 
 func (h *queueHandler) renderName(w http.ResponseWriter, r *http.Request, name string, dislikedCount int, role babynames.Role) {
-	stats, err := h.repo.GetStats(r.Context(), role)
+	progress, err := h.repo.GetProgress(r.Context(), role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 
-	progressPercentage := int((1.0 - (float64(stats.Queued) / float64(stats.Total))) * 100)
+	ratingMode, err := h.repo.GetRatingMode(r.Context(), role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	progressPercentage := int((1.0 - (float64(progress.Queued) / float64(progress.Total))) * 100)
 	model := &nameModel{
 		Name:               name,
 		Image:              getRandomImage(),
 		DislikedCount:      dislikedCount,
 		ProgressPercentage: progressPercentage,
+		CanSnooze:          true,
+		SnoozeSwipes:       h.snoozeLengths.Swipes,
+		SnoozeOptions:      snoozeOptions(h.snoozeLengths.Days),
+		RatingMode:         ratingMode,
+		Ratings:            babynames.Ratings,
+	}
+	renderPage(w, r, h.nameTemplate, model)
+}
+
+// snoozeOptions labels the numbers of days a name can be snoozed for.
+func snoozeOptions(days []int) []snoozeOption {
+	options := make([]snoozeOption, 0, len(days))
+	for _, d := range days {
+		label := fmt.Sprintf("%d days", d)
+		switch {
+		case d == 1:
+			label = "a day"
+		case d == 7:
+			label = "a week"
+		case d%7 == 0:
+			label = fmt.Sprintf("%d weeks", d/7)
+		}
+		options = append(options, snoozeOption{Days: d, Label: label})
 	}
-	renderTemplate(w, h.nameTemplate, model)
+	return options
 }
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type rateHandler struct {
+	repo  babynames.Repository
+	queue http.Handler
+}
+
+func newRateHandler(repo babynames.Repository, queue http.Handler) *rateHandler {
+	return &rateHandler{
+		repo:  repo,
+		queue: queue,
+	}
+}
+
+func (h *rateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	name := r.FormValue("name")
+	rating, err := strconv.Atoi(r.FormValue("rating"))
+	if err != nil || rating < babynames.MinRating || rating > babynames.MaxRating {
+		http.Error(w, "Invalid rating", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.Rate(r.Context(), user.Role, name, rating); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	nextInQueue(w, r, h.queue)
+}
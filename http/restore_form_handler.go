@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type restoreFormHandler struct {
+	template *pageTemplate
+}
+
+type restoreModel struct {
+	Error    string
+	Restored bool
+	Backup   babynames.Backup
+	Mode     babynames.RestoreMode
+}
+
+func newRestoreFormHandler() *restoreFormHandler {
+	return &restoreFormHandler{
+		template: parseTemplate("restore"),
+	}
+}
+
+func (h *restoreFormHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, h.template, &restoreModel{
+		Mode: babynames.RestoreMerge,
+	})
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// maxBackupSize caps the size of uploaded backups.
+const maxBackupSize = 64 << 20
+
+type restoreHandler struct {
+	template *pageTemplate
+	repo     babynames.Repository
+}
+
+func newRestoreHandler(repo babynames.Repository) *restoreHandler {
+	return &restoreHandler{
+		template: parseTemplate("restore"),
+		repo:     repo,
+	}
+}
+
+// ServeHTTP restores an uploaded backup, merging it into or replacing the existing names and votes.
+func (h *restoreHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBackupSize)
+	model := &restoreModel{
+		Mode: babynames.RestoreMode(r.FormValue("mode")),
+	}
+	if model.Mode != babynames.RestoreMerge && model.Mode != babynames.RestoreReplace {
+		http.Error(w, "Invalid restore mode", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("backup")
+	if err != nil {
+		model.Error = "Choose a backup file to restore."
+		w.WriteHeader(http.StatusBadRequest)
+		renderTemplate(w, h.template, model)
+		return
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&model.Backup); err != nil {
+		model.Error = fmt.Sprintf("The file is not a valid backup: %v", err)
+	} else if err := model.Backup.Validate(); err != nil {
+		model.Error = fmt.Sprintf("The backup can't be restored: %v", err)
+	}
+	if model.Error != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		renderTemplate(w, h.template, model)
+		return
+	}
+
+	if err := h.repo.Restore(r.Context(), model.Backup, model.Mode); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	model.Restored = true
+	renderTemplate(w, h.template, model)
+}
@@ -1,25 +1,68 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"os"
+	"time"
 
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 )
 
+// Timeouts configures how long the server waits on clients, and on in-flight requests when shutting down.
+type Timeouts struct {
+	Read     time.Duration
+	Write    time.Duration
+	Idle     time.Duration
+	Shutdown time.Duration
+}
+
 // Server defines the HTTP server serving the app.
 type Server struct {
-	port   int
-	router *mux.Router
+	port     int
+	timeouts Timeouts
+	router   *mux.Router
+
+	// errorTemplate is the page shown when a request fails, see serverError
+	errorTemplate *pageTemplate
+
+	// shuttingDown is closed when the shutdown starts, to end the long-lived event streams
+	shuttingDown chan struct{}
 }
 
-// Run starts the HTTP server.
-func (s *Server) Run() {
-	handler := handlers.LoggingHandler(os.Stdout, s.router)
-	if err := http.ListenAndServe(fmt.Sprintf(":%d", s.port), handler); err != nil {
-		panic(errors.Wrap(err, "HTTP server failed"))
+// Run serves the app until ctx is done, and then shuts down gracefully, giving in-flight requests until the
+// shutdown timeout to finish.
+func (s *Server) Run(ctx context.Context) error {
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", s.port),
+		Handler:      withRequestLogging(s.errorTemplate, s.router),
+		ReadTimeout:  s.timeouts.Read,
+		WriteTimeout: s.timeouts.Write,
+		IdleTimeout:  s.timeouts.Idle,
+	}
+
+	// The event streams never finish on their own, so they are ended as soon as the shutdown starts rather than
+	// being waited on until the shutdown times out. Every other request is left to finish.
+	server.RegisterOnShutdown(func() {
+		close(s.shuttingDown)
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return errors.Wrap(err, "HTTP server failed")
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.timeouts.Shutdown)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return errors.Wrap(err, "Unable to shut down HTTP server gracefully")
 	}
+	return nil
 }
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type settingsHandler struct {
+	template *pageTemplate
+	repo     babynames.Repository
+}
+
+type settingsModel struct {
+	DigestFrequency babynames.DigestFrequency
+	DigestLastSent  string
+	Frequencies     []babynames.DigestFrequency
+	RatingMode      bool
+	Saved           bool
+}
+
+func newSettingsHandler(repo babynames.Repository) *settingsHandler {
+	return &settingsHandler{
+		template: parseTemplate("settings"),
+		repo:     repo,
+	}
+}
+
+func (h *settingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	settings, err := h.repo.GetDigestSettings(r.Context(), user.Role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	ratingMode, err := h.repo.GetRatingMode(r.Context(), user.Role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	model := &settingsModel{
+		DigestFrequency: settings.Frequency,
+		Frequencies:     []babynames.DigestFrequency{babynames.DailyDigest, babynames.WeeklyDigest, babynames.NeverDigest},
+		RatingMode:      ratingMode,
+		Saved:           r.URL.Query().Get("saved") != "",
+	}
+	if !settings.LastSentAt.IsZero() {
+		model.DigestLastSent = settings.LastSentAt.Format("January 2, 2006 15:04")
+	}
+	renderTemplate(w, h.template, model)
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"net/http"
+)
+
+type signupFormHandler struct {
+	template *pageTemplate
+}
+
+type signupModel struct {
+	Email string
+	Error string
+}
+
+func newSignupFormHandler() *signupFormHandler {
+	return &signupFormHandler{
+		template: parseTemplate("signup"),
+	}
+}
+
+func (h *signupFormHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, h.template, &signupModel{})
+}
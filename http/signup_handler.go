@@ -0,0 +1,113 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/sessions"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type signupHandler struct {
+	template     *pageTemplate
+	sentTemplate *pageTemplate
+	siteURL      string
+	session      sessions.Store
+	repo         babynames.Repository
+	mailer       babynames.Mailer
+}
+
+func newSignupHandler(siteURL string, session sessions.Store, repo babynames.Repository, mailer babynames.Mailer) *signupHandler {
+	return &signupHandler{
+		template:     parseTemplate("signup"),
+		sentTemplate: parseTemplate("login_email_sent"),
+		siteURL:      siteURL,
+		session:      session,
+		repo:         repo,
+		mailer:       mailer,
+	}
+}
+
+// ServeHTTP creates an account for someone joining the household through an invitation. Members and guests that
+// are already in the household have to prove they own their e-mail address, so they are sent a link to choose
+// their password instead.
+func (h *signupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	emailAddress := strings.TrimSpace(r.FormValue("email"))
+	password := r.FormValue("password")
+
+	_, err := getUser(r.Context(), h.repo, emailAddress)
+	if err == nil {
+		h.sendPasswordLink(w, r, emailAddress)
+		return
+	}
+	if err != babynames.ErrNotMember {
+		serverError(w, r, err)
+		return
+	}
+	inv, ok := getPendingInvitation(r, h.session)
+	if !ok {
+		h.renderError(w, emailAddress, "This e-mail address is not a member of the household.")
+		return
+	}
+	if msg := validatePassword(password, r.FormValue("password_confirm")); msg != "" {
+		h.renderError(w, emailAddress, msg)
+		return
+	}
+
+	hash, err := babynames.HashPassword(password)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	err = h.repo.CreateInvitedAccount(r.Context(), emailAddress, hash, inv.Role, inv.Guest)
+	if err == babynames.ErrAccountExists {
+		h.renderError(w, emailAddress, "An account already exists for this e-mail address.")
+		return
+	}
+	if err == babynames.ErrMemberExists {
+		h.renderError(w, emailAddress, "Someone has already joined the household with this invitation.")
+		return
+	}
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	clearInvitation(w, r, h.session)
+
+	startSession(w, r, h.session, h.repo, emailAddress)
+}
+
+// sendPasswordLink e-mails a household member a link to choose their password, which proves they own the address.
+func (h *signupHandler) sendPasswordLink(w http.ResponseWriter, r *http.Request, emailAddress string) {
+	if h.mailer == nil {
+		h.renderError(w, emailAddress, "This e-mail address is already in the household. Ask whoever runs Babynames to set your password.")
+		return
+	}
+
+	err := sendLoginToken(
+		r.Context(),
+		h.repo,
+		h.mailer,
+		emailAddress,
+		babynames.PasswordResetToken,
+		passwordResetTTL,
+		fmt.Sprintf("%s/password/reset/confirm", h.siteURL),
+		"Choose your Babynames password",
+		"Follow the link below to choose your password for Babynames. If you did not sign up, you can ignore this e-mail.",
+	)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	renderTemplate(w, h.sentTemplate, emailAddress)
+}
+
+func (h *signupHandler) renderError(w http.ResponseWriter, emailAddress, msg string) {
+	w.WriteHeader(http.StatusBadRequest)
+	renderTemplate(w, h.template, &signupModel{
+		Email: emailAddress,
+		Error: msg,
+	})
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// SnoozeLengths configures the lengths names can be snoozed for in the queue: a number of swipes for "Maybe
+// later", and a number of days for each of the longer snoozes offered.
+type SnoozeLengths struct {
+	Swipes int
+	Days   []int
+}
+
+// allows checks if a snooze is one of the configured lengths.
+func (l SnoozeLengths) allows(snooze babynames.Snooze) bool {
+	if snooze.Days == 0 {
+		return snooze.Swipes == l.Swipes
+	}
+	if snooze.Swipes != 0 {
+		return false
+	}
+	for _, days := range l.Days {
+		if snooze.Days == days {
+			return true
+		}
+	}
+	return false
+}
+
+type snoozeHandler struct {
+	repo          babynames.Repository
+	queue         http.Handler
+	snoozeLengths SnoozeLengths
+}
+
+func newSnoozeHandler(repo babynames.Repository, queue http.Handler, snoozeLengths SnoozeLengths) *snoozeHandler {
+	return &snoozeHandler{
+		repo:          repo,
+		queue:         queue,
+		snoozeLengths: snoozeLengths,
+	}
+}
+
+func (h *snoozeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	name := r.FormValue("name")
+
+	var snooze babynames.Snooze
+	var err error
+	if v := r.FormValue("swipes"); v != "" {
+		if snooze.Swipes, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid number of swipes", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.FormValue("days"); v != "" {
+		if snooze.Days, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid number of days", http.StatusBadRequest)
+			return
+		}
+	}
+	if !h.snoozeLengths.allows(snooze) {
+		http.Error(w, "Names can only be snoozed for one of the offered lengths", http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.Snooze(r.Context(), user.Role, name, snooze)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	nextInQueue(w, r, h.queue)
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type snoozedHandler struct {
+	template *pageTemplate
+	repo     babynames.Repository
+}
+
+func newSnoozedHandler(repo babynames.Repository) *snoozedHandler {
+	return &snoozedHandler{
+		template: parseTemplate("snoozed"),
+		repo:     repo,
+	}
+}
+
+func (h *snoozedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	snoozes, err := h.repo.GetSnoozedNames(r.Context(), user.Role)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	renderTemplate(w, h.template, snoozes)
+}
@@ -1,17 +1,29 @@
 package http
 
 import (
-	"html/template"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/tanordheim/babyname-tinder"
 )
 
 type statsHandler struct {
-	template *template.Template
+	template *pageTemplate
 	repo     babynames.Repository
 }
 
+type statsModel struct {
+	babynames.Stats
+	Roles   []roleChartsModel
+	Matches chartModel
+}
+
+type roleChartsModel struct {
+	Who    string
+	Charts []chartModel
+}
+
 func newStatsHandler(repo babynames.Repository) *statsHandler {
 	return &statsHandler{
 		template: parseTemplate("stats"),
@@ -23,9 +35,40 @@ func (h *statsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	user := getCurrentUser(r.Context())
 	stats, err := h.repo.GetStats(r.Context(), user.Role)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 
-	renderTemplate(w, h.template, stats)
+	model := &statsModel{
+		Stats: stats,
+	}
+	for _, role := range babynames.Roles {
+		days, err := h.repo.GetDailyStats(r.Context(), role)
+		if err != nil {
+			serverError(w, r, err)
+			return
+		}
+
+		model.Roles = append(model.Roles, roleChartsModel{
+			Who: babynames.RoleName(role),
+			Charts: []chartModel{
+				newBarChart("Names swiped", days, func(d babynames.DailyStats) float64 { return float64(d.Swiped) }, formatCount),
+				newBarChart("Like ratio", days, babynames.DailyStats.LikeRatio, formatPercentage),
+				newBarChart("Superlikes", days, func(d babynames.DailyStats) float64 { return float64(d.Superliked) }, formatCount),
+			},
+		})
+		if role == user.Role {
+			model.Matches = newBarChart("New matches", days, func(d babynames.DailyStats) float64 { return float64(d.Matched) }, formatCount)
+		}
+	}
+
+	renderTemplate(w, h.template, model)
+}
+
+func formatCount(v float64) string {
+	return strconv.Itoa(int(v))
+}
+
+func formatPercentage(v float64) string {
+	return fmt.Sprintf("%.0f%%", v*100)
 }
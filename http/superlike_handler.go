@@ -7,12 +7,14 @@ import (
 )
 
 type superlikeHandler struct {
-	repo babynames.Repository
+	repo  babynames.Repository
+	queue http.Handler
 }
 
-func newSuperlikeHandler(repo babynames.Repository) *superlikeHandler {
+func newSuperlikeHandler(repo babynames.Repository, queue http.Handler) *superlikeHandler {
 	return &superlikeHandler{
-		repo: repo,
+		repo:  repo,
+		queue: queue,
 	}
 }
 
@@ -20,11 +22,16 @@ func (h *superlikeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	user := getCurrentUser(r.Context())
 	name := r.FormValue("name")
 
-	err := h.repo.Superlike(r.Context(), user.Role, name)
+	var err error
+	if user.Guest {
+		err = h.repo.GuestVote(r.Context(), user.EmailAddress, name, babynames.GuestSuperlike)
+	} else {
+		err = h.repo.Superlike(r.Context(), user.Role, name)
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	nextInQueue(w, r, h.queue)
 }
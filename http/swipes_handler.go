@@ -0,0 +1,58 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// maxSwipesBodySize caps the size of a batch of swipes, which holds no more than a prefetched set of cards.
+const maxSwipesBodySize = 64 << 10
+
+type swipesHandler struct {
+	repo babynames.Repository
+}
+
+type swipeModel struct {
+	Name   string                `json:"name"`
+	Action babynames.SwipeAction `json:"action"`
+}
+
+func newSwipesHandler(repo babynames.Repository) *swipesHandler {
+	return &swipesHandler{
+		repo: repo,
+	}
+}
+
+// ServeHTTP applies a batch of likes, superlikes and dislikes posted as JSON, so clients that prefetched the next
+// cards can send the swipes on them in one request. Either all of the swipes are applied or none of them are.
+func (h *swipesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req []swipeModel
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSwipesBodySize)).Decode(&req); err != nil {
+		http.Error(w, "Invalid swipes", http.StatusBadRequest)
+		return
+	}
+	if len(req) == 0 || len(req) > maxPrefetchCount {
+		http.Error(w, "Invalid number of swipes", http.StatusBadRequest)
+		return
+	}
+
+	swipes := make([]babynames.Swipe, 0, len(req))
+	for _, s := range req {
+		swipe := babynames.Swipe{Name: s.Name, Action: s.Action}
+		if swipe.Name == "" || !swipe.Valid() {
+			http.Error(w, "Invalid swipe", http.StatusBadRequest)
+			return
+		}
+		swipes = append(swipes, swipe)
+	}
+
+	user := getCurrentUser(r.Context())
+	if err := h.repo.ApplySwipes(r.Context(), user.Role, swipes); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type tagHandler struct {
+	repo babynames.Repository
+}
+
+func newTagHandler(repo babynames.Repository) *tagHandler {
+	return &tagHandler{
+		repo: repo,
+	}
+}
+
+func (h *tagHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	name := r.FormValue("name")
+	tag := normalizeTag(r.FormValue("tag"))
+	if tag == "" {
+		http.Error(w, "Invalid tag", http.StatusBadRequest)
+		return
+	}
+
+	err := h.repo.TagName(r.Context(), user.Role, name, tag)
+	if err == babynames.ErrNotLiked {
+		http.Error(w, "Only liked names can be tagged", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	// Back to the list as it was filtered when the tag was added
+	http.Redirect(w, r, tagFilterURL("/liked", normalizeTag(r.FormValue("filter"))), http.StatusSeeOther)
+}
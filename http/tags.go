@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// tagFilterModel describes the tags a list of names can be filtered by, and the tag currently filtered by.
+type tagFilterModel struct {
+	Path string
+	Tag  string
+	Tags []string
+}
+
+// normalizeTag makes tags that only differ in case or surrounding whitespace the same tag.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// newTagFilter builds the tag filter for a page from the tags by name, with the tag filtered by in the request.
+func newTagFilter(path, tag string, tags map[string][]string) tagFilterModel {
+	seen := map[string]bool{}
+	all := []string{}
+	for _, nameTags := range tags {
+		for _, t := range nameTags {
+			if !seen[t] {
+				seen[t] = true
+				all = append(all, t)
+			}
+		}
+	}
+	sort.Strings(all)
+
+	return tagFilterModel{
+		Path: path,
+		Tag:  normalizeTag(tag),
+		Tags: all,
+	}
+}
+
+// tagFilterURL returns the URL of a list of names filtered by a tag, or of the full list if the tag is empty.
+func tagFilterURL(path, tag string) string {
+	if tag == "" {
+		return path
+	}
+	return path + "?tag=" + url.QueryEscape(tag)
+}
+
+// matches checks if a name with the given tags should be shown with the filter.
+func (f tagFilterModel) matches(tags []string) bool {
+	if f.Tag == "" {
+		return true
+	}
+	for _, tag := range tags {
+		if tag == f.Tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+var (
+	// templateFiles and staticFiles hold the templates and static files served by the app.
+	templateFiles fs.FS = mustSub(babynames.Assets, "templates")
+	staticFiles   fs.FS = mustSub(babynames.Assets, "static")
+
+	// reloadTemplates makes templates get parsed again on every render, so changes show up without a restart.
+	reloadTemplates bool
+)
+
+// pageTemplate is a page template along with the layout it is rendered in.
+type pageTemplate struct {
+	name   string
+	parsed *template.Template
+}
+
+// useAssetsDir serves the templates and static files from a directory instead of the ones built into the
+// binary, reloading templates as they change. This is meant for development.
+func useAssetsDir(dir string) {
+	assets := os.DirFS(dir)
+	templateFiles = mustSub(assets, "templates")
+	staticFiles = mustSub(assets, "static")
+	reloadTemplates = true
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(errors.Wrap(err, fmt.Sprintf("Unable to open '%s'", dir)))
+	}
+	return sub
+}
+
+func parseTemplate(name string) *pageTemplate {
+	parsed, err := parsePageTemplate(name)
+	if err != nil {
+		panic(err)
+	}
+	return &pageTemplate{
+		name:   name,
+		parsed: parsed,
+	}
+}
+
+func parsePageTemplate(name string) (*template.Template, error) {
+	parsed, err := template.ParseFS(templateFiles, "layout.html", fmt.Sprintf("%s.html", name))
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Unable to parse template '%s'", name))
+	}
+	return parsed, nil
+}
+
+// execute renders one of the blocks of the template.
+func (t *pageTemplate) execute(w io.Writer, block string, data interface{}) error {
+	parsed := t.parsed
+	if reloadTemplates {
+		var err error
+		if parsed, err = parsePageTemplate(t.name); err != nil {
+			return err
+		}
+	}
+	return parsed.ExecuteTemplate(w, block, data)
+}
+
+func staticHandler() http.Handler {
+	return http.FileServer(http.FS(staticFiles))
+}
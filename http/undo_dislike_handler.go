@@ -22,7 +22,7 @@ func (h *undoDislikeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	err := h.repo.UndoDislike(r.Context(), user.Role, name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 
@@ -22,7 +22,7 @@ func (h *undoLikeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	err := h.repo.UndoLike(r.Context(), user.Role, name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, err)
 		return
 	}
 
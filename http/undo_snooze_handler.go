@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type undoSnoozeHandler struct {
+	repo babynames.Repository
+}
+
+func newUndoSnoozeHandler(repo babynames.Repository) *undoSnoozeHandler {
+	return &undoSnoozeHandler{
+		repo: repo,
+	}
+}
+
+func (h *undoSnoozeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	name := r.FormValue("name")
+
+	err := h.repo.UndoSnooze(r.Context(), user.Role, name)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/snoozed", http.StatusSeeOther)
+}
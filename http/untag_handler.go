@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type untagHandler struct {
+	repo babynames.Repository
+}
+
+func newUntagHandler(repo babynames.Repository) *untagHandler {
+	return &untagHandler{
+		repo: repo,
+	}
+}
+
+func (h *untagHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+	name := r.FormValue("name")
+	tag := normalizeTag(r.FormValue("tag"))
+
+	if err := h.repo.UntagName(r.Context(), user.Role, name, tag); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	// Back to the list as it was filtered when the tag was removed
+	http.Redirect(w, r, tagFilterURL("/liked", normalizeTag(r.FormValue("filter"))), http.StatusSeeOther)
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+type updateSettingsHandler struct {
+	repo babynames.Repository
+}
+
+func newUpdateSettingsHandler(repo babynames.Repository) *updateSettingsHandler {
+	return &updateSettingsHandler{
+		repo: repo,
+	}
+}
+
+func (h *updateSettingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUser(r.Context())
+
+	frequency := babynames.DigestFrequency(r.FormValue("digest_frequency"))
+	switch frequency {
+	case babynames.NeverDigest, babynames.DailyDigest, babynames.WeeklyDigest:
+	default:
+		http.Error(w, "Invalid digest frequency", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.SetDigestFrequency(r.Context(), user.Role, frequency); err != nil {
+		serverError(w, r, err)
+		return
+	}
+	if err := h.repo.SetRatingMode(r.Context(), user.Role, r.FormValue("rating_mode") != ""); err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
+}
@@ -9,12 +9,14 @@ import (
 type contextType int
 
 const (
-	currentUserContextKey contextType = 0
+	currentUserContextKey   contextType = 0
+	errorTemplateContextKey contextType = 1
 )
 
 type user struct {
 	EmailAddress string
 	Role         babynames.Role
+	Guest        bool
 }
 
 func newUser(email string, role babynames.Role) *user {
@@ -24,6 +26,13 @@ func newUser(email string, role babynames.Role) *user {
 	}
 }
 
+func newGuest(email string) *user {
+	return &user{
+		EmailAddress: email,
+		Guest:        true,
+	}
+}
+
 func setCurrentUser(ctx context.Context, user *user) context.Context {
 	return context.WithValue(ctx, currentUserContextKey, user)
 }
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// webhookDeliveryLogSize is the number of recent delivery attempts shown in the delivery log.
+const webhookDeliveryLogSize = 50
+
+type webhooksHandler struct {
+	template *pageTemplate
+	repo     babynames.Repository
+}
+
+type webhooksModel struct {
+	Webhooks   []babynames.Webhook
+	Deliveries []babynames.WebhookDelivery
+	EventTypes []babynames.EventType
+}
+
+func newWebhooksHandler(repo babynames.Repository) *webhooksHandler {
+	return &webhooksHandler{
+		template: parseTemplate("webhooks"),
+		repo:     repo,
+	}
+}
+
+func (h *webhooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.repo.GetWebhooks(r.Context())
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+	deliveries, err := h.repo.GetWebhookDeliveries(r.Context(), webhookDeliveryLogSize)
+	if err != nil {
+		serverError(w, r, err)
+		return
+	}
+
+	renderTemplate(w, h.template, &webhooksModel{
+		Webhooks:   webhooks,
+		Deliveries: deliveries,
+		EventTypes: babynames.EventTypes,
+	})
+}
@@ -0,0 +1,37 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// FileMailer drops e-mail as files in a directory instead of delivering them, for use during development.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+var _ babynames.Mailer = &FileMailer{}
+
+// NewFileMailer creates a new mailer writing messages to dir.
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+// Send writes a message to the mail directory.
+func (m *FileMailer) Send(ctx context.Context, msg babynames.Message) error {
+	filename := path.Join(m.dir, fmt.Sprintf("%d.eml", time.Now().UnixNano()))
+	if err := ioutil.WriteFile(filename, formatMessage(m.from, msg), 0644); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Unable to write e-mail to '%s'", filename))
+	}
+	return nil
+}
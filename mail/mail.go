@@ -0,0 +1,22 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+func formatMessage(from string, msg babynames.Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&buf, "\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
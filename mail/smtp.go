@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+
+	"github.com/pkg/errors"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// SMTPMailer delivers e-mail through an SMTP server.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+var _ babynames.Mailer = &SMTPMailer{}
+
+// NewSMTPMailer creates a new mailer delivering through the SMTP server at addr. If username is empty, no
+// authentication is performed.
+func NewSMTPMailer(addr, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: addr,
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send delivers a message.
+func (m *SMTPMailer) Send(ctx context.Context, msg babynames.Message) error {
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, formatMessage(m.from, msg)); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Unable to send e-mail to '%s'", msg.To))
+	}
+	return nil
+}
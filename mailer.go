@@ -0,0 +1,15 @@
+package babynames
+
+import "context"
+
+// Message describes an e-mail to be delivered.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer defines the e-mail delivery behavior.
+type Mailer interface {
+	Send(context.Context, Message) error
+}
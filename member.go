@@ -0,0 +1,24 @@
+package babynames
+
+import (
+	"errors"
+	"time"
+)
+
+// InvitationTTL is how long a partner invitation link stays valid.
+const InvitationTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrNotMember is returned when looking up an e-mail address that is not a member of the household.
+	ErrNotMember = errors.New("not a member of the household")
+
+	// ErrMemberExists is returned when adding a member whose e-mail address or role is already taken.
+	ErrMemberExists = errors.New("e-mail address or role is already a member of the household")
+)
+
+// Member describes an e-mail address that has joined the household with a role.
+type Member struct {
+	Email    string
+	Role     Role
+	JoinedAt time.Time
+}
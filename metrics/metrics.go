@@ -0,0 +1,162 @@
+// Package metrics exposes the app's metrics in the Prometheus text format.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// collectTimeout bounds the repository queries made when the domain gauges are scraped.
+const collectTimeout = 5 * time.Second
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "babynames",
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests handled, by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "babynames",
+		Name:      "http_request_duration_seconds",
+		Help:      "Time spent handling HTTP requests, by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	repositoryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "babynames",
+		Name:      "repository_call_duration_seconds",
+		Help:      "Time spent in repository calls, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	repositoryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "babynames",
+		Name:      "repository_errors_total",
+		Help:      "Number of failed repository calls, by method.",
+	}, []string{"method"})
+
+	namesDesc   = prometheus.NewDesc("babynames_names", "Number of names in the database.", nil, nil)
+	queuedDesc  = prometheus.NewDesc("babynames_queued_names", "Number of names left in the queue, by role.", []string{"role"}, nil)
+	matchesDesc = prometheus.NewDesc("babynames_matches", "Number of names liked by both roles.", nil, nil)
+)
+
+// Register registers the app's metrics, including the domain gauges read from repo on every scrape.
+func Register(repo babynames.Repository) {
+	prometheus.MustRegister(httpRequests, httpDuration, repositoryDuration, repositoryErrors, &domainCollector{repo: repo})
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware records the count and duration of requests per route. It must be installed with the router's Use so
+// the matched route is known.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		httpDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+	})
+}
+
+// statusRecorder captures the status code written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap exposes the underlying response writer to http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Flush passes flushes through, so streamed responses like the event stream keep working.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// domainCollector reads the domain gauges from the repository when metrics are scraped.
+type domainCollector struct {
+	repo babynames.Repository
+}
+
+func (c *domainCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- namesDesc
+	ch <- queuedDesc
+	ch <- matchesDesc
+}
+
+func (c *domainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	for idx, role := range babynames.Roles {
+		progress, err := c.repo.GetProgress(ctx, role)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(queuedDesc, err)
+			continue
+		}
+
+		// The total is the same for both roles, so only report it once
+		if idx == 0 {
+			ch <- prometheus.MustNewConstMetric(namesDesc, prometheus.GaugeValue, float64(progress.Total))
+		}
+		ch <- prometheus.MustNewConstMetric(queuedDesc, prometheus.GaugeValue, float64(progress.Queued), babynames.RoleName(role))
+	}
+
+	matches, err := c.repo.CountMatches(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(matchesDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(matchesDesc, prometheus.GaugeValue, float64(matches))
+}
+
+// Serve serves the metrics on addr until ctx is done.
+func Serve(ctx context.Context, addr string) error {
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      Handler(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return errors.Wrap(err, "Metrics server failed")
+	case <-ctx.Done():
+		return server.Close()
+	}
+}
@@ -0,0 +1,456 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// Repository decorates a repository, recording the duration and errors of every call made through it.
+type Repository struct {
+	babynames.Repository
+}
+
+var _ babynames.Repository = &Repository{}
+
+// NewRepository creates a new repository recording metrics for the calls made to repo.
+func NewRepository(repo babynames.Repository) *Repository {
+	return &Repository{
+		Repository: repo,
+	}
+}
+
+// observe records the duration of a repository call, and counts it as an error if it failed.
+func observe(method string, start time.Time, err *error) {
+	repositoryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if *err != nil {
+		repositoryErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// ImportNames imports a set of names to the database if they don't exist already.
+func (r *Repository) ImportNames(ctx context.Context, names []string) (err error) {
+	defer observe("ImportNames", time.Now(), &err)
+	return r.Repository.ImportNames(ctx, names)
+}
+
+// Like flags a name as liked for the specified role.
+func (r *Repository) Like(ctx context.Context, role babynames.Role, name string) (err error) {
+	defer observe("Like", time.Now(), &err)
+	return r.Repository.Like(ctx, role, name)
+}
+
+// Superlike flags a name as super-liked for the specified role.
+func (r *Repository) Superlike(ctx context.Context, role babynames.Role, name string) (err error) {
+	defer observe("Superlike", time.Now(), &err)
+	return r.Repository.Superlike(ctx, role, name)
+}
+
+// UndoLike removes a like for a name, putting it back in the queue.
+func (r *Repository) UndoLike(ctx context.Context, role babynames.Role, name string) (err error) {
+	defer observe("UndoLike", time.Now(), &err)
+	return r.Repository.UndoLike(ctx, role, name)
+}
+
+// Rate gives a name a rating for the specified role. Ratings at or above the like threshold count as likes, while
+// lower ratings count as dislikes.
+func (r *Repository) Rate(ctx context.Context, role babynames.Role, name string, rating int) (err error) {
+	defer observe("Rate", time.Now(), &err)
+	return r.Repository.Rate(ctx, role, name, rating)
+}
+
+// Dislike flags a name as disliked for the specified role, returning the number of times the role has disliked the name.
+func (r *Repository) Dislike(ctx context.Context, role babynames.Role, name string) (res int, err error) {
+	defer observe("Dislike", time.Now(), &err)
+	res, err = r.Repository.Dislike(ctx, role, name)
+	return
+}
+
+// ApplySwipes likes, superlikes and dislikes a batch of names for the specified role in a single transaction.
+func (r *Repository) ApplySwipes(ctx context.Context, role babynames.Role, swipes []babynames.Swipe) (err error) {
+	defer observe("ApplySwipes", time.Now(), &err)
+	return r.Repository.ApplySwipes(ctx, role, swipes)
+}
+
+// UndoDislike removes a dislike for a name, putting it back in the queue.
+func (r *Repository) UndoDislike(ctx context.Context, role babynames.Role, name string) (err error) {
+	defer observe("UndoDislike", time.Now(), &err)
+	return r.Repository.UndoDislike(ctx, role, name)
+}
+
+// Snooze puts off a name for the specified role, either by moving it further back in the queue or by hiding it
+// from the queue for a number of days. Snoozes don't count as dislikes.
+func (r *Repository) Snooze(ctx context.Context, role babynames.Role, name string, snooze babynames.Snooze) (err error) {
+	defer observe("Snooze", time.Now(), &err)
+	return r.Repository.Snooze(ctx, role, name, snooze)
+}
+
+// UndoSnooze brings a snoozed name back to the front of the role's queue.
+func (r *Repository) UndoSnooze(ctx context.Context, role babynames.Role, name string) (err error) {
+	defer observe("UndoSnooze", time.Now(), &err)
+	return r.Repository.UndoSnooze(ctx, role, name)
+}
+
+// GetSnoozedNames gets a list of all names snoozed by the role that are still in the queue.
+func (r *Repository) GetSnoozedNames(ctx context.Context, role babynames.Role) (res []babynames.SnoozedName, err error) {
+	defer observe("GetSnoozedNames", time.Now(), &err)
+	res, err = r.Repository.GetSnoozedNames(ctx, role)
+	return
+}
+
+// GetPendingSuperlike gets any pending superlikes that requires the role's attention.
+func (r *Repository) GetPendingSuperlike(ctx context.Context, role babynames.Role) (res string, err error) {
+	defer observe("GetPendingSuperlike", time.Now(), &err)
+	res, err = r.Repository.GetPendingSuperlike(ctx, role)
+	return
+}
+
+// GetPendingSuperlikes gets all pending superlikes that require the role's attention.
+func (r *Repository) GetPendingSuperlikes(ctx context.Context, role babynames.Role) (res []string, err error) {
+	defer observe("GetPendingSuperlikes", time.Now(), &err)
+	res, err = r.Repository.GetPendingSuperlikes(ctx, role)
+	return
+}
+
+// GetAndAcknowledgeUnseenMatch returns a matched name between both roles that the specified role has not yet seen. This function will flag the name as seen in the process.
+func (r *Repository) GetAndAcknowledgeUnseenMatch(ctx context.Context, role babynames.Role) (res string, err error) {
+	defer observe("GetAndAcknowledgeUnseenMatch", time.Now(), &err)
+	res, err = r.Repository.GetAndAcknowledgeUnseenMatch(ctx, role)
+	return
+}
+
+// GetNextName gets the next name in the queue for the role.
+func (r *Repository) GetNextName(ctx context.Context, role babynames.Role) (name string, dislikes int, err error) {
+	defer observe("GetNextName", time.Now(), &err)
+	name, dislikes, err = r.Repository.GetNextName(ctx, role)
+	return
+}
+
+// GetNextNames gets the next n names in the shuffled queue for the role. The same names are returned in the same
+// order until they're liked or disliked.
+func (r *Repository) GetNextNames(ctx context.Context, role babynames.Role, n int) (res []babynames.QueuedName, err error) {
+	defer observe("GetNextNames", time.Now(), &err)
+	res, err = r.Repository.GetNextNames(ctx, role, n)
+	return
+}
+
+// GetLikedNames gets a list of all liked names by the role.
+func (r *Repository) GetLikedNames(ctx context.Context, role babynames.Role) (res []babynames.LikedName, err error) {
+	defer observe("GetLikedNames", time.Now(), &err)
+	res, err = r.Repository.GetLikedNames(ctx, role)
+	return
+}
+
+// GetDislikedNames gets a list of all disliked names by the role.
+func (r *Repository) GetDislikedNames(ctx context.Context, role babynames.Role) (res []babynames.DislikedName, err error) {
+	defer observe("GetDislikedNames", time.Now(), &err)
+	res, err = r.Repository.GetDislikedNames(ctx, role)
+	return
+}
+
+// GetMatches gets a list of all names that are matched with the other role, with the strongest matches first.
+func (r *Repository) GetMatches(ctx context.Context, role babynames.Role) (res []babynames.Match, err error) {
+	defer observe("GetMatches", time.Now(), &err)
+	res, err = r.Repository.GetMatches(ctx, role)
+	return
+}
+
+// IsMatch checks if a name has been liked by both roles.
+func (r *Repository) IsMatch(ctx context.Context, name string) (res bool, err error) {
+	defer observe("IsMatch", time.Now(), &err)
+	res, err = r.Repository.IsMatch(ctx, name)
+	return
+}
+
+// GetStats retrieves the progression stats of a role.
+func (r *Repository) GetStats(ctx context.Context, role babynames.Role) (res babynames.Stats, err error) {
+	defer observe("GetStats", time.Now(), &err)
+	res, err = r.Repository.GetStats(ctx, role)
+	return
+}
+
+// GetProgress retrieves how many names there are and how many of them are still in the queue of a role.
+func (r *Repository) GetProgress(ctx context.Context, role babynames.Role) (res babynames.Progress, err error) {
+	defer observe("GetProgress", time.Now(), &err)
+	res, err = r.Repository.GetProgress(ctx, role)
+	return
+}
+
+// CountMatches counts the names liked by both roles.
+func (r *Repository) CountMatches(ctx context.Context) (res int, err error) {
+	defer observe("CountMatches", time.Now(), &err)
+	res, err = r.Repository.CountMatches(ctx)
+	return
+}
+
+// GetDailyStats gets the swiping activity of a role per day, from the first day it swiped until today.
+func (r *Repository) GetDailyStats(ctx context.Context, role babynames.Role) (res []babynames.DailyStats, err error) {
+	defer observe("GetDailyStats", time.Now(), &err)
+	res, err = r.Repository.GetDailyStats(ctx, role)
+	return
+}
+
+// GetAgreement compares the votes of the two roles; how often they agree, how much more than by chance (Cohen's
+// kappa), the names they disagree on and how many of their likes turned into matches.
+func (r *Repository) GetAgreement(ctx context.Context) (res babynames.Agreement, err error) {
+	defer observe("GetAgreement", time.Now(), &err)
+	res, err = r.Repository.GetAgreement(ctx)
+	return
+}
+
+// AddNote attaches a note to a name for the role, optionally sharing it with the other role.
+func (r *Repository) AddNote(ctx context.Context, role babynames.Role, name string, text string, shared bool) (err error) {
+	defer observe("AddNote", time.Now(), &err)
+	return r.Repository.AddNote(ctx, role, name, text, shared)
+}
+
+// DeleteNote deletes one of the role's notes.
+func (r *Repository) DeleteNote(ctx context.Context, role babynames.Role, id int) (err error) {
+	defer observe("DeleteNote", time.Now(), &err)
+	return r.Repository.DeleteNote(ctx, role, id)
+}
+
+// GetNotes gets a list of all notes visible to the role; its own notes and the notes shared by the other role.
+func (r *Repository) GetNotes(ctx context.Context, role babynames.Role) (res []babynames.Note, err error) {
+	defer observe("GetNotes", time.Now(), &err)
+	res, err = r.Repository.GetNotes(ctx, role)
+	return
+}
+
+// TagName adds a personal tag to a name liked by the role.
+func (r *Repository) TagName(ctx context.Context, role babynames.Role, name string, tag string) (err error) {
+	defer observe("TagName", time.Now(), &err)
+	return r.Repository.TagName(ctx, role, name, tag)
+}
+
+// UntagName removes a personal tag from a name.
+func (r *Repository) UntagName(ctx context.Context, role babynames.Role, name string, tag string) (err error) {
+	defer observe("UntagName", time.Now(), &err)
+	return r.Repository.UntagName(ctx, role, name, tag)
+}
+
+// GetTags gets the role's personal tags on the names it has liked, keyed by name.
+func (r *Repository) GetTags(ctx context.Context, role babynames.Role) (res map[string][]string, err error) {
+	defer observe("GetTags", time.Now(), &err)
+	res, err = r.Repository.GetTags(ctx, role)
+	return
+}
+
+// CreateAccount creates a local account with the specified password hash.
+func (r *Repository) CreateAccount(ctx context.Context, email string, passwordHash string) (err error) {
+	defer observe("CreateAccount", time.Now(), &err)
+	return r.Repository.CreateAccount(ctx, email, passwordHash)
+}
+
+// CreateInvitedAccount adds an e-mail address to the household as a member with the specified role, or as a guest,
+// and creates a local account for it in the same transaction, so a failed join doesn't leave an account behind.
+func (r *Repository) CreateInvitedAccount(ctx context.Context, email string, passwordHash string, role babynames.Role, guest bool) (err error) {
+	defer observe("CreateInvitedAccount", time.Now(), &err)
+	return r.Repository.CreateInvitedAccount(ctx, email, passwordHash, role, guest)
+}
+
+// GetPasswordHash gets the password hash of a local account, or an empty string if the account does not exist.
+func (r *Repository) GetPasswordHash(ctx context.Context, email string) (res string, err error) {
+	defer observe("GetPasswordHash", time.Now(), &err)
+	res, err = r.Repository.GetPasswordHash(ctx, email)
+	return
+}
+
+// SetPasswordHash sets the password hash of a local account, creating the account if it does not exist.
+func (r *Repository) SetPasswordHash(ctx context.Context, email string, passwordHash string) (err error) {
+	defer observe("SetPasswordHash", time.Now(), &err)
+	return r.Repository.SetPasswordHash(ctx, email, passwordHash)
+}
+
+// CreateLoginToken stores a one-time login token for an e-mail address.
+func (r *Repository) CreateLoginToken(ctx context.Context, email string, purpose babynames.LoginTokenPurpose, tokenHash string, expiresAt time.Time) (err error) {
+	defer observe("CreateLoginToken", time.Now(), &err)
+	return r.Repository.CreateLoginToken(ctx, email, purpose, tokenHash, expiresAt)
+}
+
+// ConsumeLoginToken flags a one-time login token as used, returning the e-mail address it was issued to. An empty
+// string is returned if the token does not exist, has expired or has already been used.
+func (r *Repository) ConsumeLoginToken(ctx context.Context, purpose babynames.LoginTokenPurpose, tokenHash string) (res string, err error) {
+	defer observe("ConsumeLoginToken", time.Now(), &err)
+	res, err = r.Repository.ConsumeLoginToken(ctx, purpose, tokenHash)
+	return
+}
+
+// GetRoleForEmail gets the role of a household member, returning babynames.ErrNotMember if the e-mail address
+// has not joined the household.
+func (r *Repository) GetRoleForEmail(ctx context.Context, email string) (res babynames.Role, err error) {
+	defer observe("GetRoleForEmail", time.Now(), &err)
+	res, err = r.Repository.GetRoleForEmail(ctx, email)
+	return
+}
+
+// AddMember adds an e-mail address to the household with the specified role, returning babynames.ErrMemberExists
+// if either the e-mail address or the role is already taken.
+func (r *Repository) AddMember(ctx context.Context, email string, role babynames.Role) (err error) {
+	defer observe("AddMember", time.Now(), &err)
+	return r.Repository.AddMember(ctx, email, role)
+}
+
+// GetMembers gets a list of all members of the household.
+func (r *Repository) GetMembers(ctx context.Context) (res []babynames.Member, err error) {
+	defer observe("GetMembers", time.Now(), &err)
+	res, err = r.Repository.GetMembers(ctx)
+	return
+}
+
+// AddGuest adds an e-mail address as a guest voter, if it isn't one already.
+func (r *Repository) AddGuest(ctx context.Context, email string) (err error) {
+	defer observe("AddGuest", time.Now(), &err)
+	return r.Repository.AddGuest(ctx, email)
+}
+
+// IsGuest checks if an e-mail address is a guest voter.
+func (r *Repository) IsGuest(ctx context.Context, email string) (res bool, err error) {
+	defer observe("IsGuest", time.Now(), &err)
+	res, err = r.Repository.IsGuest(ctx, email)
+	return
+}
+
+// GetGuests gets a list of all guest voters.
+func (r *Repository) GetGuests(ctx context.Context) (res []babynames.Guest, err error) {
+	defer observe("GetGuests", time.Now(), &err)
+	res, err = r.Repository.GetGuests(ctx)
+	return
+}
+
+// GuestVote records the vote of a guest on a name, replacing any previous vote by the guest.
+func (r *Repository) GuestVote(ctx context.Context, email string, name string, vote babynames.GuestVote) (err error) {
+	defer observe("GuestVote", time.Now(), &err)
+	return r.Repository.GuestVote(ctx, email, name, vote)
+}
+
+// GetNextGuestName gets the next name in the queue for a guest.
+func (r *Repository) GetNextGuestName(ctx context.Context, email string) (res string, err error) {
+	defer observe("GetNextGuestName", time.Now(), &err)
+	res, err = r.Repository.GetNextGuestName(ctx, email)
+	return
+}
+
+// GetGuestOpinions gets the aggregated guest votes of all names guests have voted on, keyed by name.
+func (r *Repository) GetGuestOpinions(ctx context.Context) (res map[string]babynames.GuestOpinion, err error) {
+	defer observe("GetGuestOpinions", time.Now(), &err)
+	res, err = r.Repository.GetGuestOpinions(ctx)
+	return
+}
+
+// GetDigestSettings gets the e-mail digest settings of a role.
+func (r *Repository) GetDigestSettings(ctx context.Context, role babynames.Role) (res babynames.DigestSettings, err error) {
+	defer observe("GetDigestSettings", time.Now(), &err)
+	res, err = r.Repository.GetDigestSettings(ctx, role)
+	return
+}
+
+// SetDigestFrequency sets how often a role receives the e-mail digest.
+func (r *Repository) SetDigestFrequency(ctx context.Context, role babynames.Role, frequency babynames.DigestFrequency) (err error) {
+	defer observe("SetDigestFrequency", time.Now(), &err)
+	return r.Repository.SetDigestFrequency(ctx, role, frequency)
+}
+
+// MarkDigestSent records when the e-mail digest was last sent to a role.
+func (r *Repository) MarkDigestSent(ctx context.Context, role babynames.Role, sentAt time.Time) (err error) {
+	defer observe("MarkDigestSent", time.Now(), &err)
+	return r.Repository.MarkDigestSent(ctx, role, sentAt)
+}
+
+// GetRatingMode checks if a role rates names from 1 to 5 instead of liking them.
+func (r *Repository) GetRatingMode(ctx context.Context, role babynames.Role) (res bool, err error) {
+	defer observe("GetRatingMode", time.Now(), &err)
+	res, err = r.Repository.GetRatingMode(ctx, role)
+	return
+}
+
+// SetRatingMode sets if a role rates names from 1 to 5 instead of liking them.
+func (r *Repository) SetRatingMode(ctx context.Context, role babynames.Role, ratingMode bool) (err error) {
+	defer observe("SetRatingMode", time.Now(), &err)
+	return r.Repository.SetRatingMode(ctx, role, ratingMode)
+}
+
+// GetWebhooks gets a list of all outgoing webhooks.
+func (r *Repository) GetWebhooks(ctx context.Context) (res []babynames.Webhook, err error) {
+	defer observe("GetWebhooks", time.Now(), &err)
+	res, err = r.Repository.GetWebhooks(ctx)
+	return
+}
+
+// AddWebhook adds an outgoing webhook, signing its payloads with secret.
+func (r *Repository) AddWebhook(ctx context.Context, url string, secret string) (err error) {
+	defer observe("AddWebhook", time.Now(), &err)
+	return r.Repository.AddWebhook(ctx, url, secret)
+}
+
+// DeleteWebhook deletes an outgoing webhook along with its delivery log.
+func (r *Repository) DeleteWebhook(ctx context.Context, id int) (err error) {
+	defer observe("DeleteWebhook", time.Now(), &err)
+	return r.Repository.DeleteWebhook(ctx, id)
+}
+
+// LogWebhookDelivery records an attempt at delivering an event to a webhook.
+func (r *Repository) LogWebhookDelivery(ctx context.Context, delivery babynames.WebhookDelivery) (err error) {
+	defer observe("LogWebhookDelivery", time.Now(), &err)
+	return r.Repository.LogWebhookDelivery(ctx, delivery)
+}
+
+// GetWebhookDeliveries gets the most recent webhook delivery attempts, newest first.
+func (r *Repository) GetWebhookDeliveries(ctx context.Context, limit int) (res []babynames.WebhookDelivery, err error) {
+	defer observe("GetWebhookDeliveries", time.Now(), &err)
+	res, err = r.Repository.GetWebhookDeliveries(ctx, limit)
+	return
+}
+
+// PruneWebhookDeliveries removes the webhook delivery attempts made before the given time.
+func (r *Repository) PruneWebhookDeliveries(ctx context.Context, before time.Time) (err error) {
+	defer observe("PruneWebhookDeliveries", time.Now(), &err)
+	return r.Repository.PruneWebhookDeliveries(ctx, before)
+}
+
+// GetEvents gets up to limit events recorded after the event with the given ID, oldest first.
+func (r *Repository) GetEvents(ctx context.Context, afterID int64, limit int) (res []babynames.Event, err error) {
+	defer observe("GetEvents", time.Now(), &err)
+	res, err = r.Repository.GetEvents(ctx, afterID, limit)
+	return
+}
+
+// GetLastEventID gets the ID of the most recently recorded event, or 0 if there are none.
+func (r *Repository) GetLastEventID(ctx context.Context) (res int64, err error) {
+	defer observe("GetLastEventID", time.Now(), &err)
+	res, err = r.Repository.GetLastEventID(ctx)
+	return
+}
+
+// GetEventCursor gets the ID of the last event a consumer has handled.
+func (r *Repository) GetEventCursor(ctx context.Context, consumer string) (res int64, err error) {
+	defer observe("GetEventCursor", time.Now(), &err)
+	res, err = r.Repository.GetEventCursor(ctx, consumer)
+	return
+}
+
+// SetEventCursor records the ID of the last event a consumer has handled.
+func (r *Repository) SetEventCursor(ctx context.Context, consumer string, id int64) (err error) {
+	defer observe("SetEventCursor", time.Now(), &err)
+	return r.Repository.SetEventCursor(ctx, consumer, id)
+}
+
+// PruneEvents removes the events recorded before the given time that every consumer has handled.
+func (r *Repository) PruneEvents(ctx context.Context, before time.Time) (err error) {
+	defer observe("PruneEvents", time.Now(), &err)
+	return r.Repository.PruneEvents(ctx, before)
+}
+
+// Backup gets a copy of all names along with the votes, notes, tags and swipes on them.
+func (r *Repository) Backup(ctx context.Context) (res babynames.Backup, err error) {
+	defer observe("Backup", time.Now(), &err)
+	res, err = r.Repository.Backup(ctx)
+	return
+}
+
+// Restore restores a backup in a single transaction, merging it into or replacing the existing data.
+func (r *Repository) Restore(ctx context.Context, backup babynames.Backup, mode babynames.RestoreMode) (err error) {
+	defer observe("Restore", time.Now(), &err)
+	return r.Repository.Restore(ctx, backup, mode)
+}
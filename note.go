@@ -0,0 +1,14 @@
+package babynames
+
+import "time"
+
+// Note describes a note a role has attached to a name. Private notes are only visible to the role that wrote them,
+// while shared notes are visible to the partner as well.
+type Note struct {
+	ID        int
+	Role      Role
+	Name      string
+	Text      string
+	Shared    bool
+	CreatedAt time.Time
+}
@@ -0,0 +1,431 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// Backup gets a copy of all names along with the votes, notes, tags and swipes on them. It is read from a single
+// snapshot of the database, so it is consistent even while mom and dad keep swiping.
+func (r *Repository) Backup(ctx context.Context) (babynames.Backup, error) {
+	backup := babynames.Backup{
+		Version:   babynames.BackupVersion,
+		CreatedAt: time.Now(),
+	}
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return backup, wrap(ctx, err, "Unable to start PostgreSQL transaction")
+	}
+	defer tx.Rollback()
+
+	// Columns are named after the lowercased fields they are read into
+	queries := []struct {
+		what  string
+		dest  interface{}
+		query string
+	}{
+		{"names", &backup.Names, `SELECT name FROM names ORDER BY name`},
+		{"likes", &backup.Likes, `
+			SELECT
+				likes.role_id AS role,
+				names.name,
+				likes.liked_at AS likedat,
+				likes.superlike,
+				COALESCE(likes.rating, 0) AS rating,
+				likes.previously_disliked AS previouslydisliked
+			FROM likes
+			INNER JOIN names ON names.id = likes.name_id
+			ORDER BY likes.role_id, likes.liked_at
+		`},
+		{"dislikes", &backup.Dislikes, `
+			SELECT
+				dislikes.role_id AS role,
+				names.name,
+				dislikes.disliked_first_at AS firstat,
+				dislikes.disliked_last_at AS lastat,
+				dislikes.disliked_times AS times
+			FROM dislikes
+			INNER JOIN names ON names.id = dislikes.name_id
+			ORDER BY dislikes.role_id, dislikes.disliked_first_at
+		`},
+		{"acknowledged matches", &backup.AcknowledgedMatches, `
+			SELECT
+				acknowledged_matches.role_id AS role,
+				names.name,
+				acknowledged_matches.acknowledged_at AS acknowledgedat
+			FROM acknowledged_matches
+			INNER JOIN names ON names.id = acknowledged_matches.name_id
+			ORDER BY acknowledged_matches.role_id, acknowledged_matches.acknowledged_at
+		`},
+		{"notes", &backup.Notes, `
+			SELECT
+				notes.role_id AS role,
+				names.name,
+				notes.text,
+				notes.shared,
+				notes.created_at AS createdat
+			FROM notes
+			INNER JOIN names ON names.id = notes.name_id
+			ORDER BY notes.id
+		`},
+		{"tags", &backup.Tags, `
+			SELECT
+				tags.role_id AS role,
+				names.name,
+				tags.tag,
+				tags.tagged_at AS taggedat
+			FROM tags
+			INNER JOIN names ON names.id = tags.name_id
+			ORDER BY tags.role_id, tags.tagged_at
+		`},
+		{"swipes", &backup.Swipes, `
+			SELECT
+				swipes.role_id AS role,
+				names.name,
+				swipes.action,
+				swipes.swiped_at AS swipedat
+			FROM swipes
+			INNER JOIN names ON names.id = swipes.name_id
+			ORDER BY swipes.id
+		`},
+	}
+	for _, q := range queries {
+		if err := tx.SelectContext(ctx, q.dest, q.query); err != nil {
+			return backup, wrap(ctx, err, fmt.Sprintf("Unable to back up %s", q.what))
+		}
+	}
+
+	rows, err := tx.QueryxContext(
+		ctx,
+		`
+			SELECT
+				snoozes.role_id,
+				names.name,
+				snoozes.snoozed_times,
+				snoozes.snoozed_last_at,
+				snoozes.snoozed_until
+			FROM snoozes
+			INNER JOIN names ON names.id = snoozes.name_id
+			ORDER BY snoozes.role_id, snoozes.snoozed_last_at
+		`,
+	)
+	if err != nil {
+		return backup, wrap(ctx, err, "Unable to back up snoozes")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var snooze babynames.BackupSnooze
+		var until pq.NullTime
+		if err := rows.Scan(&snooze.Role, &snooze.Name, &snooze.Times, &snooze.LastSnoozedAt, &until); err != nil {
+			return backup, wrap(ctx, err, "Unable to read snooze")
+		}
+		snooze.Until = until.Time
+		backup.Snoozes = append(backup.Snoozes, snooze)
+	}
+	if err := rows.Err(); err != nil {
+		return backup, wrap(ctx, err, "Unable to back up snoozes")
+	}
+
+	return backup, nil
+}
+
+// Restore restores a backup in a single transaction, so nothing changes unless all of it is restored. When
+// replacing, the names and everything done with them are removed first, except for names still in the backup.
+// When merging, the votes in the backup win over existing votes on the same names, while notes, tags and swipes
+// already there are kept alongside the ones in the backup.
+func (r *Repository) Restore(ctx context.Context, backup babynames.Backup, mode babynames.RestoreMode) error {
+	if mode != babynames.RestoreMerge && mode != babynames.RestoreReplace {
+		return fmt.Errorf("Unknown restore mode '%s'", mode)
+	}
+	if err := backup.Validate(); err != nil {
+		return err
+	}
+
+	return r.withTX(ctx, func(tx *sqlx.Tx) error {
+		if mode == babynames.RestoreReplace {
+			if err := r.clearForRestore(ctx, tx, backup.Names); err != nil {
+				return err
+			}
+		}
+
+		// Names new to the household, and names the backup took out of the likes, are added to the back of the queues
+		var requeue []string
+		for _, name := range backup.Names {
+			res, err := tx.ExecContext(ctx, `INSERT INTO names (id, name) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`, getIDForName(name), name)
+			if err != nil {
+				return wrap(ctx, err, fmt.Sprintf("Unable to restore name '%s'", name))
+			}
+			if n, err := res.RowsAffected(); err == nil && n > 0 {
+				requeue = append(requeue, getIDForName(name))
+			}
+		}
+
+		for _, like := range backup.Likes {
+			if _, err := r.removeDislikeFor(ctx, tx, like.Role, like.Name); err != nil {
+				return err
+			}
+			if err := r.dequeueFor(ctx, tx, like.Role, like.Name); err != nil {
+				return err
+			}
+			var rating sql.NullInt64
+			if like.Rating > 0 {
+				rating = sql.NullInt64{Int64: int64(like.Rating), Valid: true}
+			}
+			_, err := tx.ExecContext(
+				ctx,
+				`
+					INSERT INTO likes (
+						role_id,
+						name_id,
+						liked_at,
+						superlike,
+						rating,
+						previously_disliked
+					) VALUES (
+						$1,
+						$2,
+						$3,
+						$4,
+						$5,
+						$6
+					) ON CONFLICT (role_id, name_id) DO UPDATE SET
+						liked_at = EXCLUDED.liked_at,
+						superlike = EXCLUDED.superlike,
+						rating = EXCLUDED.rating,
+						previously_disliked = EXCLUDED.previously_disliked
+				`,
+				like.Role,
+				getIDForName(like.Name),
+				like.LikedAt,
+				like.Superlike,
+				rating,
+				like.PreviouslyDisliked,
+			)
+			if err != nil {
+				return wrap(ctx, err, fmt.Sprintf("Unable to restore like of name '%s' by role '%v'", like.Name, like.Role))
+			}
+		}
+
+		for _, dislike := range backup.Dislikes {
+			if _, err := r.removeLikeFor(ctx, tx, dislike.Role, dislike.Name); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(
+				ctx,
+				`
+					INSERT INTO dislikes (
+						role_id,
+						name_id,
+						disliked_first_at,
+						disliked_last_at,
+						disliked_times
+					) VALUES (
+						$1,
+						$2,
+						$3,
+						$4,
+						$5
+					) ON CONFLICT (role_id, name_id) DO UPDATE SET
+						disliked_first_at = EXCLUDED.disliked_first_at,
+						disliked_last_at = EXCLUDED.disliked_last_at,
+						disliked_times = EXCLUDED.disliked_times
+				`,
+				dislike.Role,
+				getIDForName(dislike.Name),
+				dislike.FirstAt,
+				dislike.LastAt,
+				dislike.Times,
+			)
+			if err != nil {
+				return wrap(ctx, err, fmt.Sprintf("Unable to restore dislike of name '%s' by role '%v'", dislike.Name, dislike.Role))
+			}
+			if dislike.Times >= babynames.DislikesBeforeRemoved {
+				if err := r.dequeueFor(ctx, tx, dislike.Role, dislike.Name); err != nil {
+					return err
+				}
+			} else {
+				requeue = append(requeue, getIDForName(dislike.Name))
+			}
+		}
+
+		for _, match := range backup.AcknowledgedMatches {
+			_, err := tx.ExecContext(
+				ctx,
+				`
+					INSERT INTO acknowledged_matches (
+						role_id,
+						name_id,
+						acknowledged_at
+					) VALUES (
+						$1,
+						$2,
+						$3
+					) ON CONFLICT (role_id, name_id) DO NOTHING
+				`,
+				match.Role,
+				getIDForName(match.Name),
+				match.AcknowledgedAt,
+			)
+			if err != nil {
+				return wrap(ctx, err, fmt.Sprintf("Unable to restore acknowledged match on name '%s' for role '%v'", match.Name, match.Role))
+			}
+		}
+
+		for _, snooze := range backup.Snoozes {
+			var until pq.NullTime
+			if !snooze.Until.IsZero() {
+				until = pq.NullTime{Time: snooze.Until, Valid: true}
+			}
+			_, err := tx.ExecContext(
+				ctx,
+				`
+					INSERT INTO snoozes (
+						role_id,
+						name_id,
+						snoozed_times,
+						snoozed_last_at,
+						snoozed_until
+					) VALUES (
+						$1,
+						$2,
+						$3,
+						$4,
+						$5
+					) ON CONFLICT (role_id, name_id) DO UPDATE SET
+						snoozed_times = EXCLUDED.snoozed_times,
+						snoozed_last_at = EXCLUDED.snoozed_last_at,
+						snoozed_until = EXCLUDED.snoozed_until
+				`,
+				snooze.Role,
+				getIDForName(snooze.Name),
+				snooze.Times,
+				snooze.LastSnoozedAt,
+				until,
+			)
+			if err != nil {
+				return wrap(ctx, err, fmt.Sprintf("Unable to restore snooze of name '%s' for role '%v'", snooze.Name, snooze.Role))
+			}
+		}
+
+		// Notes and swipes have no natural key, so the ones already there are skipped to keep restoring the same
+		// backup twice from duplicating them
+		for _, note := range backup.Notes {
+			_, err := tx.ExecContext(
+				ctx,
+				`
+					INSERT INTO notes (
+						role_id,
+						name_id,
+						text,
+						shared,
+						created_at
+					)
+					SELECT $1, $2, $3, $4, $5
+					WHERE NOT EXISTS (
+						SELECT 1 FROM notes
+						WHERE role_id = $1 AND name_id = $2 AND text = $3 AND created_at = $5
+					)
+				`,
+				note.Role,
+				getIDForName(note.Name),
+				note.Text,
+				note.Shared,
+				note.CreatedAt,
+			)
+			if err != nil {
+				return wrap(ctx, err, fmt.Sprintf("Unable to restore note on name '%s' by role '%v'", note.Name, note.Role))
+			}
+		}
+
+		for _, tag := range backup.Tags {
+			_, err := tx.ExecContext(
+				ctx,
+				`
+					INSERT INTO tags (
+						role_id,
+						name_id,
+						tag,
+						tagged_at
+					) VALUES (
+						$1,
+						$2,
+						$3,
+						$4
+					) ON CONFLICT (role_id, name_id, tag) DO NOTHING
+				`,
+				tag.Role,
+				getIDForName(tag.Name),
+				tag.Tag,
+				tag.TaggedAt,
+			)
+			if err != nil {
+				return wrap(ctx, err, fmt.Sprintf("Unable to restore tag '%s' on name '%s' for role '%v'", tag.Tag, tag.Name, tag.Role))
+			}
+		}
+
+		for _, swipe := range backup.Swipes {
+			_, err := tx.ExecContext(
+				ctx,
+				`
+					INSERT INTO swipes (
+						role_id,
+						name_id,
+						action,
+						swiped_at
+					)
+					SELECT $1, $2, $3, $4
+					WHERE NOT EXISTS (
+						SELECT 1 FROM swipes
+						WHERE role_id = $1 AND name_id = $2 AND action = $3 AND swiped_at = $4
+					)
+				`,
+				swipe.Role,
+				getIDForName(swipe.Name),
+				swipe.Action,
+				swipe.SwipedAt,
+			)
+			if err != nil {
+				return wrap(ctx, err, fmt.Sprintf("Unable to restore %s of name '%s' by role '%v'", swipe.Action, swipe.Name, swipe.Role))
+			}
+		}
+
+		// Merging leaves the queues as they are, apart from the names the backup changed, so the order mom and dad
+		// swipe in and their snoozes are kept
+		if mode == babynames.RestoreReplace {
+			return r.shuffleQueues(ctx, tx)
+		}
+		return r.appendToQueues(ctx, tx, requeue)
+	})
+}
+
+// clearForRestore removes everything done with the names before a backup replaces them, along with the names
+// that aren't in the backup.
+func (r *Repository) clearForRestore(ctx context.Context, tx *sqlx.Tx, keep []string) error {
+	for _, table := range []string{"likes", "dislikes", "acknowledged_matches", "snoozes", "notes", "tags", "swipes", "queue_order", "guest_queue_order"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", table)); err != nil {
+			return wrap(ctx, err, fmt.Sprintf("Unable to clear %s", table))
+		}
+	}
+
+	ids := make([]string, len(keep))
+	for i, name := range keep {
+		ids[i] = getIDForName(name)
+	}
+
+	// Guest votes aren't part of backups, so they are only removed along with their names
+	if _, err := tx.ExecContext(ctx, "DELETE FROM guest_votes WHERE NOT (name_id = ANY($1))", pq.Array(ids)); err != nil {
+		return wrap(ctx, err, "Unable to clear guest votes on removed names")
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM names WHERE NOT (id = ANY($1))", pq.Array(ids)); err != nil {
+		return wrap(ctx, err, "Unable to clear names")
+	}
+	return nil
+}
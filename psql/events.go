@@ -0,0 +1,227 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+// eventsLockID is the advisory lock taken while recording events, see recordEventFor.
+const eventsLockID = 5318009
+
+// recordEventFor records an event in the same transaction as the change that caused it, so the event is only
+// seen by consumers if the change is committed. Transactions recording events are serialized until they commit,
+// so events become visible in the order of their IDs and consumers reading past an ID never miss one committed
+// later with a lower ID.
+func (r *Repository) recordEventFor(ctx context.Context, tx *sqlx.Tx, event babynames.Event) error {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", eventsLockID); err != nil {
+		return wrap(ctx, err, "Unable to lock events")
+	}
+
+	var role sql.NullInt64
+	var name sql.NullString
+	if event.Type != babynames.ImportCompletedEvent {
+		role = sql.NullInt64{Int64: int64(event.Role), Valid: true}
+		name = sql.NullString{String: event.Name, Valid: true}
+	}
+
+	_, err := tx.ExecContext(
+		ctx,
+		`
+			INSERT INTO events (
+				type,
+				role_id,
+				name,
+				count,
+				occurred_at
+			) VALUES (
+				$1,
+				$2,
+				$3,
+				$4,
+				CURRENT_TIMESTAMP
+			)
+		`,
+		event.Type,
+		role,
+		name,
+		event.Count,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to record %s event", event.Type))
+	}
+	return nil
+}
+
+// withMatchEventsFor runs a change to a name's likes, recording an event if it made the name a match or broke
+// one. The name is locked first, so mom and dad changing their minds about the same name at the same time can't
+// both see themselves as the one creating or breaking the match.
+func (r *Repository) withMatchEventsFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string, f func() error) error {
+	if _, err := tx.ExecContext(ctx, "SELECT 1 FROM names WHERE id = $1 FOR UPDATE", getIDForName(name)); err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to lock name '%s'", name))
+	}
+
+	wasMatch, err := r.isMatchFor(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+
+	if err := f(); err != nil {
+		return err
+	}
+
+	isMatch, err := r.isMatchFor(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+	if !wasMatch && isMatch {
+		return r.recordEventFor(ctx, tx, babynames.Event{Type: babynames.MatchCreatedEvent, Role: role, Name: name})
+	}
+	if wasMatch && !isMatch {
+		return r.recordEventFor(ctx, tx, babynames.Event{Type: babynames.MatchBrokenEvent, Role: role, Name: name})
+	}
+	return nil
+}
+
+func (r *Repository) isMatchFor(ctx context.Context, q sqlx.QueryerContext, name string) (bool, error) {
+	var match bool
+	row := q.QueryRowxContext(
+		ctx,
+		"SELECT COUNT(1) = 2 FROM likes WHERE name_id = $1 AND role_id IN ($2, $3)",
+		getIDForName(name),
+		babynames.MomRole,
+		babynames.DadRole,
+	)
+	if err := row.Scan(&match); err != nil {
+		return false, wrap(ctx, err, fmt.Sprintf("Unable to check if name '%s' is a match", name))
+	}
+	return match, nil
+}
+
+// GetEvents gets up to limit events recorded after the event with the given ID, oldest first.
+func (r *Repository) GetEvents(ctx context.Context, afterID int64, limit int) ([]babynames.Event, error) {
+	rows, err := r.db.QueryxContext(
+		ctx,
+		`
+			SELECT
+				id,
+				type,
+				role_id,
+				name,
+				count,
+				occurred_at
+			FROM
+				events
+			WHERE
+				id > $1
+			ORDER BY id
+			LIMIT $2
+		`,
+		afterID,
+		limit,
+	)
+	if err != nil {
+		return nil, wrap(ctx, err, "Unable to get events")
+	}
+	defer rows.Close()
+
+	events := []babynames.Event{}
+	for rows.Next() {
+		var event babynames.Event
+		var role sql.NullInt64
+		var name sql.NullString
+		if err := rows.Scan(&event.ID, &event.Type, &role, &name, &event.Count, &event.At); err != nil {
+			return nil, wrap(ctx, err, "Unable to read event")
+		}
+		event.Role = babynames.Role(role.Int64)
+		event.Name = name.String
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrap(ctx, err, "Unable to get events")
+	}
+	return events, nil
+}
+
+// GetLastEventID gets the ID of the most recently recorded event, or 0 if there are none.
+func (r *Repository) GetLastEventID(ctx context.Context) (int64, error) {
+	var id int64
+	if err := r.db.QueryRowxContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM events").Scan(&id); err != nil {
+		return 0, wrap(ctx, err, "Unable to get the last event")
+	}
+	return id, nil
+}
+
+// GetEventCursor gets the ID of the last event a consumer has handled. Consumers seen for the first time start
+// from the most recently recorded event, rather than handling every event ever recorded.
+func (r *Repository) GetEventCursor(ctx context.Context, consumer string) (int64, error) {
+	var id int64
+	err := r.db.QueryRowxContext(
+		ctx,
+		`
+			WITH created AS (
+				INSERT INTO event_cursors (
+					consumer,
+					event_id
+				)
+				SELECT $1, COALESCE(MAX(id), 0) FROM events
+				ON CONFLICT (consumer) DO NOTHING
+				RETURNING event_id
+			)
+			SELECT event_id FROM created
+			UNION ALL
+			SELECT event_id FROM event_cursors WHERE consumer = $1
+		`,
+		consumer,
+	).Scan(&id)
+	if err != nil {
+		return 0, wrap(ctx, err, fmt.Sprintf("Unable to get event cursor of '%s'", consumer))
+	}
+	return id, nil
+}
+
+// SetEventCursor records the ID of the last event a consumer has handled.
+func (r *Repository) SetEventCursor(ctx context.Context, consumer string, id int64) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO event_cursors (
+				consumer,
+				event_id
+			) VALUES (
+				$1,
+				$2
+			) ON CONFLICT (consumer) DO UPDATE SET
+				event_id = EXCLUDED.event_id
+		`,
+		consumer,
+		id,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to set event cursor of '%s'", consumer))
+	}
+	return nil
+}
+
+// PruneEvents removes the events recorded before the given time that every consumer has handled.
+func (r *Repository) PruneEvents(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			DELETE FROM
+				events
+			WHERE
+				occurred_at < $1 AND
+				id <= (SELECT COALESCE(MIN(event_id), 0) FROM event_cursors)
+		`,
+		before,
+	)
+	if err != nil {
+		return wrap(ctx, err, "Unable to prune events")
+	}
+	return nil
+}
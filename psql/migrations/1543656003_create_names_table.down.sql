@@ -0,0 +1 @@
+DROP TABLE names;
\ No newline at end of file
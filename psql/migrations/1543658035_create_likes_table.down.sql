@@ -0,0 +1 @@
+DROP TABLE likes;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE dislikes;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE acknowledged_matches;
\ No newline at end of file
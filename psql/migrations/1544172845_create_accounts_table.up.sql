@@ -0,0 +1,6 @@
+CREATE TABLE accounts (
+    email TEXT NOT NULL PRIMARY KEY,
+    password_hash TEXT NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE login_tokens (
+    token_hash TEXT NOT NULL PRIMARY KEY,
+    email TEXT NOT NULL,
+    purpose TEXT NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    used_at timestamp with time zone
+);
\ No newline at end of file
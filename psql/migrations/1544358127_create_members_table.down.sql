@@ -0,0 +1 @@
+DROP TABLE members;
\ No newline at end of file
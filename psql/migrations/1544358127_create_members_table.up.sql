@@ -0,0 +1,5 @@
+CREATE TABLE members (
+    email TEXT NOT NULL PRIMARY KEY,
+    role_id int NOT NULL UNIQUE,
+    joined_at timestamp with time zone NOT NULL
+);
\ No newline at end of file
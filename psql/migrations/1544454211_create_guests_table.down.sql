@@ -0,0 +1 @@
+DROP TABLE guests;
\ No newline at end of file
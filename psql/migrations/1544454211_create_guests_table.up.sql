@@ -0,0 +1,4 @@
+CREATE TABLE guests (
+    email TEXT NOT NULL PRIMARY KEY,
+    joined_at timestamp with time zone NOT NULL
+);
\ No newline at end of file
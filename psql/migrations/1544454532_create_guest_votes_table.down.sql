@@ -0,0 +1 @@
+DROP TABLE guest_votes;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE guest_votes (
+    email TEXT NOT NULL REFERENCES guests (email),
+    name_id TEXT NOT NULL REFERENCES names (id),
+    vote TEXT NOT NULL,
+    voted_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (email, name_id)
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE digest_settings;
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE digest_settings (
+    role_id int NOT NULL PRIMARY KEY,
+    frequency TEXT NOT NULL,
+    last_sent_at timestamp with time zone
+);
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE webhooks (
+    id serial NOT NULL PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE webhook_deliveries (
+    id serial NOT NULL PRIMARY KEY,
+    webhook_id int NOT NULL REFERENCES webhooks (id),
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    attempt int NOT NULL,
+    status_code int NOT NULL,
+    error TEXT NOT NULL,
+    succeeded bool NOT NULL,
+    attempted_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_attempted_at_idx ON webhook_deliveries (attempted_at);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE queue_order;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE queue_order (
+    role_id int NOT NULL,
+    name_id TEXT NOT NULL REFERENCES names (id),
+    position int NOT NULL,
+    PRIMARY KEY (role_id, name_id)
+);
+
+CREATE INDEX queue_order_position_idx ON queue_order (role_id, position);
+
+-- The queues are filled after migrating, see Migrator.Up
\ No newline at end of file
@@ -0,0 +1,11 @@
+DROP TABLE snoozes;
+
+UPDATE queue_order
+SET position = ordered.position
+FROM (
+    SELECT role_id, name_id, row_number() OVER (PARTITION BY role_id ORDER BY position) AS position
+    FROM queue_order
+) AS ordered
+WHERE queue_order.role_id = ordered.role_id AND queue_order.name_id = ordered.name_id;
+
+ALTER TABLE queue_order ALTER COLUMN position TYPE int;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE queue_order ALTER COLUMN position TYPE double precision;
+
+CREATE TABLE snoozes (
+    role_id int NOT NULL,
+    name_id TEXT NOT NULL REFERENCES names (id),
+    snoozed_times int NOT NULL,
+    snoozed_last_at timestamp with time zone NOT NULL,
+    snoozed_until timestamp with time zone,
+    PRIMARY KEY (role_id, name_id)
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE notes (
+    id serial NOT NULL PRIMARY KEY,
+    role_id int NOT NULL,
+    name_id TEXT NOT NULL REFERENCES names (id),
+    text TEXT NOT NULL,
+    shared bool NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX notes_name_id_idx ON notes (name_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE tags;
\ No newline at end of file
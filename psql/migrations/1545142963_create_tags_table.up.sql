@@ -0,0 +1,7 @@
+CREATE TABLE tags (
+    role_id int NOT NULL,
+    name_id TEXT NOT NULL REFERENCES names (id),
+    tag TEXT NOT NULL,
+    tagged_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (role_id, name_id, tag)
+);
\ No newline at end of file
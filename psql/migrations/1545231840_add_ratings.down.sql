@@ -0,0 +1,3 @@
+DROP TABLE role_settings;
+
+ALTER TABLE likes DROP COLUMN rating;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE likes ADD COLUMN rating int;
+
+CREATE TABLE role_settings (
+    role_id int NOT NULL PRIMARY KEY,
+    rating_mode bool NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE likes DROP COLUMN previously_disliked;
\ No newline at end of file
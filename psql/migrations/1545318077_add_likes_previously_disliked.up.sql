@@ -0,0 +1 @@
+ALTER TABLE likes ADD COLUMN previously_disliked bool NOT NULL DEFAULT 'f';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE swipes;
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE swipes (
+    id serial NOT NULL PRIMARY KEY,
+    role_id int NOT NULL,
+    name_id TEXT NOT NULL REFERENCES names (id),
+    action TEXT NOT NULL,
+    swiped_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX swipes_role_id_swiped_at_idx ON swipes (role_id, swiped_at);
+
+INSERT INTO swipes (role_id, name_id, action, swiped_at)
+SELECT role_id, name_id, CASE WHEN superlike THEN 'superlike' ELSE 'like' END, liked_at FROM likes;
+
+INSERT INTO swipes (role_id, name_id, action, swiped_at)
+SELECT role_id, name_id, 'dislike', disliked_first_at FROM dislikes;
+
+INSERT INTO swipes (role_id, name_id, action, swiped_at)
+SELECT role_id, name_id, 'dislike', disliked_last_at FROM dislikes WHERE disliked_times > 1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE event_cursors;
+
+DROP TABLE events;
\ No newline at end of file
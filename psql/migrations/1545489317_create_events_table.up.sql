@@ -0,0 +1,15 @@
+CREATE TABLE events (
+    id bigserial NOT NULL PRIMARY KEY,
+    type TEXT NOT NULL,
+    role_id int,
+    name TEXT,
+    count int NOT NULL DEFAULT 0,
+    occurred_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX events_occurred_at_idx ON events (occurred_at);
+
+CREATE TABLE event_cursors (
+    consumer TEXT NOT NULL PRIMARY KEY,
+    event_id bigint NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+-- Likes can't tell which of them were backfilled, so they are left as they are
\ No newline at end of file
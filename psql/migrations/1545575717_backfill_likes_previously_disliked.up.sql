@@ -0,0 +1,9 @@
+UPDATE likes SET previously_disliked = 't'
+WHERE EXISTS (
+    SELECT 1 FROM swipes
+    WHERE
+        swipes.role_id = likes.role_id AND
+        swipes.name_id = likes.name_id AND
+        swipes.action = 'dislike' AND
+        swipes.swiped_at < likes.liked_at
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE guest_queue_order;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE guest_queue_order (
+    name_id TEXT NOT NULL REFERENCES names (id) PRIMARY KEY,
+    position int NOT NULL
+);
+
+CREATE INDEX guest_queue_order_position_idx ON guest_queue_order (position);
+
+-- The queue is filled after migrating, see Migrator.Up
\ No newline at end of file
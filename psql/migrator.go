@@ -0,0 +1,203 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/go_bindata"
+	"github.com/pkg/errors"
+)
+
+// migrationLockID identifies the advisory lock held while migrating. It is taken before the migration tool's own
+// lock, which gives up after a few seconds, so an instance waits for as long as another one is migrating.
+const migrationLockID = 5318008
+
+// migrationFiles holds the schema migrations, so they don't have to be shipped alongside the binary.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is a schema migration.
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// MigrationStatus describes the schema version of a database and the migrations available.
+type MigrationStatus struct {
+	Version    uint
+	Dirty      bool
+	Migrations []Migration
+}
+
+// Migrator manages the schema migrations of a PostgreSQL database.
+type Migrator struct {
+	db       *sql.DB
+	lock     *sql.Conn
+	migrator *migrate.Migrate
+}
+
+// NewMigrator connects to the database to manage its schema migrations. It waits until no other instance is
+// migrating the database, and keeps others waiting until it is closed.
+func NewMigrator(ctx context.Context, connstr string) (*Migrator, error) {
+	db, err := sql.Open("postgres", connstr)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to connect to PostgreSQL")
+	}
+
+	lock, err := db.Conn(ctx)
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "Unable to connect to PostgreSQL")
+	}
+	if _, err := lock.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		lock.Close()
+		db.Close()
+		return nil, errors.Wrap(err, "Unable to take the schema migration lock")
+	}
+
+	m := &Migrator{
+		db:   db,
+		lock: lock,
+	}
+	migrateDriver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		m.Close()
+		return nil, errors.Wrap(err, "Unable to prepare schema migrations")
+	}
+	migrations, err := openMigrations()
+	if err != nil {
+		m.Close()
+		return nil, errors.Wrap(err, "Unable to read schema migrations")
+	}
+	m.migrator, err = migrate.NewWithInstance("go-bindata", migrations, "postgres", migrateDriver)
+	if err != nil {
+		m.Close()
+		return nil, errors.Wrap(err, "Unable to prepare schema migrations")
+	}
+
+	return m, nil
+}
+
+// Close releases the migration lock and disconnects from the database.
+func (m *Migrator) Close() error {
+	_, err := m.lock.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+	m.lock.Close()
+	if m.migrator != nil {
+		// Closing the migration tool closes the database as well
+		m.migrator.Close()
+	} else {
+		m.db.Close()
+	}
+	return errors.Wrap(err, "Unable to release the schema migration lock")
+}
+
+// Up performs all pending migrations. Afterwards any names missing from the queues are queued, as the
+// migrations creating the queues leave them empty rather than depend on the roles and rules of the app.
+func (m *Migrator) Up() error {
+	if err := m.migrator.Up(); err != nil && err != migrate.ErrNoChange {
+		return errors.Wrap(err, "Unable to perform schema migrations")
+	}
+	return fillQueues(context.Background(), m.db)
+}
+
+// Down reverts the given number of migrations.
+func (m *Migrator) Down(steps int) error {
+	if err := m.migrator.Steps(-steps); err != nil && err != migrate.ErrNoChange {
+		return errors.Wrap(err, fmt.Sprintf("Unable to revert %d schema migration(s)", steps))
+	}
+	return nil
+}
+
+// Goto migrates up or down to the given version.
+func (m *Migrator) Goto(version uint) error {
+	if err := m.migrator.Migrate(version); err != nil && err != migrate.ErrNoChange {
+		return errors.Wrap(err, fmt.Sprintf("Unable to migrate to schema version %d", version))
+	}
+	return nil
+}
+
+// Status returns the schema version of the database along with the migrations available.
+func (m *Migrator) Status() (MigrationStatus, error) {
+	var status MigrationStatus
+	version, dirty, err := m.migrator.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return status, errors.Wrap(err, "Unable to get schema version")
+	}
+	status.Version = version
+	status.Dirty = dirty
+
+	status.Migrations, err = listMigrations()
+	if err != nil {
+		return status, errors.Wrap(err, "Unable to list schema migrations")
+	}
+	for i := range status.Migrations {
+		status.Migrations[i].Applied = status.Migrations[i].Version <= version
+	}
+	return status, nil
+}
+
+// Migrate performs all pending migrations on the database.
+func Migrate(ctx context.Context, connstr string) error {
+	m, err := NewMigrator(ctx, connstr)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return m.Up()
+}
+
+// openMigrations opens the embedded schema migrations as a migration source.
+func openMigrations() (source.Driver, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+
+	return bindata.WithInstance(bindata.Resource(names, func(name string) ([]byte, error) {
+		return migrationFiles.ReadFile(path.Join("migrations", name))
+	}))
+}
+
+// listMigrations returns the schema migrations available, in order.
+func listMigrations() ([]Migration, error) {
+	src, err := openMigrations()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	var migrations []Migration
+	version, err := src.First()
+	for err == nil {
+		var r io.ReadCloser
+		var name string
+		r, name, err = src.ReadUp(version)
+		if err != nil {
+			return nil, err
+		}
+		r.Close()
+		migrations = append(migrations, Migration{Version: version, Name: name})
+
+		version, err = src.Next(version)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return migrations, nil
+}
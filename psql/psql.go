@@ -1,6 +1,9 @@
 package psql
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
@@ -8,34 +11,58 @@ import (
 	// Import PostgreSQL driver
 	_ "github.com/lib/pq"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-
-	// Import file migration driver
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-
 	"github.com/pkg/errors"
 )
 
-// NewRepository creates a new PostgreSQL repository.
+// NewRepository creates a new PostgreSQL repository. The schema is expected to be migrated separately, through
+// Migrate or a Migrator.
 func NewRepository(connstr string) *Repository {
 	db, err := sqlx.Connect("postgres", connstr)
 	if err != nil {
 		panic(errors.Wrap(err, "Unable to connect to PostgreSQL"))
 	}
 
-	migrateDriver, err := postgres.WithInstance(db.DB, &postgres.Config{})
-	migrator, err := migrate.NewWithDatabaseInstance("file://psql/migrations", "postgres", migrateDriver)
+	migrations, err := listMigrations()
 	if err != nil {
-		panic(errors.Wrap(err, "Unable to prepare schema migrations"))
+		panic(errors.Wrap(err, "Unable to read schema migrations"))
 	}
-	if err := migrator.Up(); err != nil && err != migrate.ErrNoChange {
-		panic(errors.Wrap(err, "Unable to perform schema migrations"))
+	if len(migrations) == 0 {
+		panic(errors.New("No schema migrations found"))
 	}
 
 	return &Repository{
-		db: db,
+		db:            db,
+		schemaVersion: migrations[len(migrations)-1].Version,
+	}
+}
+
+// CheckReady checks that the database can be reached and that its schema is up to date.
+func (r *Repository) CheckReady(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return wrap(ctx, err, "Unable to reach PostgreSQL")
+	}
+
+	var version uint
+	var dirty bool
+	err := r.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("No schema migrations have been performed, expected version %d", r.schemaVersion)
 	}
+	if err != nil {
+		return wrap(ctx, err, "Unable to get schema version")
+	}
+	if dirty {
+		return fmt.Errorf("Schema migration to version %d did not complete", version)
+	}
+	if version != r.schemaVersion {
+		return fmt.Errorf("Schema is at version %d, expected version %d", version, r.schemaVersion)
+	}
+	return nil
+}
+
+// Close closes the connection pool to the database.
+func (r *Repository) Close() error {
+	return r.db.Close()
 }
 
 func getIDForName(name string) string {
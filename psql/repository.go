@@ -4,24 +4,43 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/tanordheim/babyname-tinder"
 )
 
+// Swipe actions recorded in the swipe log.
+const (
+	swipeLike      = "like"
+	swipeSuperlike = "superlike"
+	swipeDislike   = "dislike"
+	swipeSnooze    = "snooze"
+)
+
 // Repository encapsulates all PostgreSQL storage mechanics.
 type Repository struct {
-	db *sqlx.DB
+	db            *sqlx.DB
+	schemaVersion uint
 }
 
 var _ babynames.Repository = &Repository{}
 
+// wrap annotates err with message, and with the ID of the request it happened in so it can be traced in the logs.
+func wrap(ctx context.Context, err error, message string) error {
+	if requestID := babynames.RequestID(ctx); requestID != "" {
+		message = fmt.Sprintf("%s (request %s)", message, requestID)
+	}
+	return errors.Wrap(err, message)
+}
+
 func (r *Repository) withTX(ctx context.Context, f func(*sqlx.Tx) error) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return errors.Wrap(err, "Unable to start PostgreSQL transaction")
+		return wrap(ctx, err, "Unable to start PostgreSQL transaction")
 	}
 	defer tx.Rollback()
 
@@ -30,7 +49,7 @@ func (r *Repository) withTX(ctx context.Context, f func(*sqlx.Tx) error) error {
 	}
 
 	if err := tx.Commit(); err != nil {
-		return errors.Wrap(err, "Unable to commit PostgreSQL transaction")
+		return wrap(ctx, err, "Unable to commit PostgreSQL transaction")
 	}
 	return nil
 }
@@ -49,22 +68,169 @@ func (r *Repository) ImportNames(ctx context.Context, names []string) error {
 			) ON CONFLICT (id) DO NOTHING
 		`)
 		if err != nil {
-			return errors.Wrap(err, "Unable to prepare insert statement")
+			return wrap(ctx, err, "Unable to prepare insert statement")
 		}
 
+		var added []string
 		for i := 0; i < len(names); i++ {
-			_, err := stmt.ExecContext(ctx, getIDForName(names[i]), names[i])
+			res, err := stmt.ExecContext(ctx, getIDForName(names[i]), names[i])
 			if err != nil {
-				return errors.Wrap(err, fmt.Sprintf("Unable to insert name %s", names[i]))
+				return wrap(ctx, err, fmt.Sprintf("Unable to insert name %s", names[i]))
+			}
+			if n, err := res.RowsAffected(); err == nil && n > 0 {
+				added = append(added, getIDForName(names[i]))
 			}
 		}
 
-		return nil
+		if err := r.appendToQueues(ctx, tx, added); err != nil {
+			return err
+		}
+		return r.recordEventFor(ctx, tx, babynames.Event{Type: babynames.ImportCompletedEvent, Count: len(names)})
 	})
 }
 
-func (r *Repository) removeLikeFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string) error {
+// appendToQueues adds names to the back of every role's queue and of the guest queue in random order, leaving the
+// names already queued where they are. Names a role has liked, or disliked too many times, are left out.
+func (r *Repository) appendToQueues(ctx context.Context, tx *sqlx.Tx, nameIDs []string) error {
+	if len(nameIDs) == 0 {
+		return nil
+	}
+
+	roles := make([]int64, len(babynames.Roles))
+	for i, role := range babynames.Roles {
+		roles[i] = int64(role)
+	}
+
 	_, err := tx.ExecContext(
+		ctx,
+		`
+			INSERT INTO queue_order (
+				role_id,
+				name_id,
+				position
+			)
+			SELECT
+				roles.role_id,
+				added.name_id,
+				COALESCE((SELECT MAX(position) FROM queue_order WHERE role_id = roles.role_id), 0) +
+					row_number() OVER (PARTITION BY roles.role_id ORDER BY random())
+			FROM
+				unnest($2::text[]) AS added (name_id)
+			CROSS JOIN unnest($1::int[]) AS roles (role_id)
+			LEFT JOIN likes ON likes.role_id = roles.role_id AND likes.name_id = added.name_id
+			LEFT JOIN dislikes ON dislikes.role_id = roles.role_id AND dislikes.name_id = added.name_id
+			WHERE
+				likes.name_id IS NULL AND
+				(dislikes.name_id IS NULL OR dislikes.disliked_times < $3)
+			ON CONFLICT (role_id, name_id) DO NOTHING
+		`,
+		pq.Array(roles),
+		pq.Array(nameIDs),
+		babynames.DislikesBeforeRemoved,
+	)
+	if err != nil {
+		return wrap(ctx, err, "Unable to add names to the queues")
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`
+			INSERT INTO guest_queue_order (
+				name_id,
+				position
+			)
+			SELECT
+				added.name_id,
+				COALESCE((SELECT MAX(position) FROM guest_queue_order), 0) + row_number() OVER (ORDER BY random())
+			FROM
+				unnest($1::text[]) AS added (name_id)
+			ON CONFLICT (name_id) DO NOTHING
+		`,
+		pq.Array(nameIDs),
+	)
+	if err != nil {
+		return wrap(ctx, err, "Unable to add names to the guest queue")
+	}
+	return nil
+}
+
+// fillQueues adds the names missing from the queues to the back of them in random order, the same way
+// appendToQueues does. Names are queued as they are imported, so this only adds any when the queues have just
+// been created or cleared.
+func fillQueues(ctx context.Context, db sqlx.ExecerContext) error {
+	roles := make([]int64, len(babynames.Roles))
+	for i, role := range babynames.Roles {
+		roles[i] = int64(role)
+	}
+
+	_, err := db.ExecContext(
+		ctx,
+		`
+			INSERT INTO queue_order (
+				role_id,
+				name_id,
+				position
+			)
+			SELECT
+				roles.role_id,
+				names.id,
+				COALESCE((SELECT MAX(position) FROM queue_order WHERE role_id = roles.role_id), 0) +
+					row_number() OVER (PARTITION BY roles.role_id ORDER BY random())
+			FROM
+				names
+			CROSS JOIN unnest($1::int[]) AS roles (role_id)
+			LEFT JOIN queue_order ON queue_order.role_id = roles.role_id AND queue_order.name_id = names.id
+			LEFT JOIN likes ON likes.role_id = roles.role_id AND likes.name_id = names.id
+			LEFT JOIN dislikes ON dislikes.role_id = roles.role_id AND dislikes.name_id = names.id
+			WHERE
+				queue_order.name_id IS NULL AND
+				likes.name_id IS NULL AND
+				(dislikes.name_id IS NULL OR dislikes.disliked_times < $2)
+		`,
+		pq.Array(roles),
+		babynames.DislikesBeforeRemoved,
+	)
+	if err != nil {
+		return wrap(ctx, err, "Unable to fill the queues")
+	}
+
+	_, err = db.ExecContext(
+		ctx,
+		`
+			INSERT INTO guest_queue_order (
+				name_id,
+				position
+			)
+			SELECT
+				names.id,
+				COALESCE((SELECT MAX(position) FROM guest_queue_order), 0) + row_number() OVER (ORDER BY random())
+			FROM
+				names
+			LEFT JOIN guest_queue_order ON guest_queue_order.name_id = names.id
+			WHERE
+				guest_queue_order.name_id IS NULL
+		`,
+	)
+	if err != nil {
+		return wrap(ctx, err, "Unable to fill the guest queue")
+	}
+	return nil
+}
+
+// shuffleQueues regenerates the shuffled queue order of every role and of guests, so fetching the next names
+// doesn't have to randomize the full name list on every request.
+func (r *Repository) shuffleQueues(ctx context.Context, tx *sqlx.Tx) error {
+	for _, table := range []string{"queue_order", "guest_queue_order"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", table)); err != nil {
+			return wrap(ctx, err, fmt.Sprintf("Unable to clear %s", table))
+		}
+	}
+	return fillQueues(ctx, tx)
+}
+
+// removeLikeFor removes the role's like of a name, returning whether there was one.
+func (r *Repository) removeLikeFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string) (bool, error) {
+	res, err := tx.ExecContext(
 		ctx,
 		`
 			DELETE FROM
@@ -77,17 +243,132 @@ func (r *Repository) removeLikeFor(ctx context.Context, tx *sqlx.Tx, role babyna
 		role,
 	)
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("Unable to remove any existing likes on name %s for role %v", name, role))
+		return false, wrap(ctx, err, fmt.Sprintf("Unable to remove any existing likes on name %s for role %v", name, role))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, wrap(ctx, err, fmt.Sprintf("Unable to remove any existing likes on name %s for role %v", name, role))
+	}
+	return n > 0, nil
+}
+
+// removeDislikeFor removes the role's dislike of a name, returning whether there was one.
+func (r *Repository) removeDislikeFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string) (bool, error) {
+	res, err := tx.ExecContext(
+		ctx,
+		`
+			DELETE FROM
+				dislikes
+			WHERE
+				name_id = $1 AND
+				role_id = $2
+		`,
+		getIDForName(name),
+		role,
+	)
+	if err != nil {
+		return false, wrap(ctx, err, fmt.Sprintf("Unable to remove any existing dislikes on name %s for role %v", name, role))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, wrap(ctx, err, fmt.Sprintf("Unable to remove any existing dislikes on name %s for role %v", name, role))
+	}
+	return n > 0, nil
+}
+
+// logSwipeFor records a queue action in the swipe log, which the daily stats are built from.
+func (r *Repository) logSwipeFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name, action string) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`
+			INSERT INTO swipes (
+				role_id,
+				name_id,
+				action,
+				swiped_at
+			) VALUES (
+				$1,
+				$2,
+				$3,
+				CURRENT_TIMESTAMP
+			)
+		`,
+		role,
+		getIDForName(name),
+		action,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to log %s of name %s for role %v", action, name, role))
 	}
 	return nil
 }
 
-func (r *Repository) removeDislikeFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string) error {
+// unlogSwipeFor removes the most recent of the role's swipes on a name with one of the given actions from the
+// swipe log, for when the swipe is undone.
+func (r *Repository) unlogSwipeFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string, actions ...string) error {
 	_, err := tx.ExecContext(
 		ctx,
 		`
 			DELETE FROM
-				dislikes
+				swipes
+			WHERE
+				id = (
+					SELECT
+						id
+					FROM
+						swipes
+					WHERE
+						role_id = $1 AND
+						name_id = $2 AND
+						action = ANY($3)
+					ORDER BY swiped_at DESC, id DESC
+					LIMIT 1
+				)
+		`,
+		role,
+		getIDForName(name),
+		pq.Array(actions),
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to remove undone swipe of name %s for role %v from the log", name, role))
+	}
+	return nil
+}
+
+// enqueueFor puts a name at the back of the role's queue, unless the role has liked it.
+func (r *Repository) enqueueFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`
+			INSERT INTO queue_order (
+				role_id,
+				name_id,
+				position
+			)
+			SELECT
+				$1,
+				$2,
+				COALESCE((SELECT MAX(position) FROM queue_order WHERE role_id = $1), 0) + 1
+			WHERE
+				NOT EXISTS (SELECT 1 FROM likes WHERE role_id = $1 AND name_id = $2)
+			ON CONFLICT (role_id, name_id) DO UPDATE SET
+				position = EXCLUDED.position
+		`,
+		role,
+		getIDForName(name),
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to queue name %s for role %v", name, role))
+	}
+	return nil
+}
+
+func (r *Repository) dequeueFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`
+			DELETE FROM
+				queue_order
 			WHERE
 				name_id = $1 AND
 				role_id = $2
@@ -96,7 +377,7 @@ func (r *Repository) removeDislikeFor(ctx context.Context, tx *sqlx.Tx, role bab
 		role,
 	)
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("Unable to remove any existing dislikes on name %s for role %v", name, role))
+		return wrap(ctx, err, fmt.Sprintf("Unable to remove name %s from the queue for role %v", name, role))
 	}
 	return nil
 }
@@ -104,74 +385,177 @@ func (r *Repository) removeDislikeFor(ctx context.Context, tx *sqlx.Tx, role bab
 // Like flags a name as liked for the specified role.
 func (r *Repository) Like(ctx context.Context, role babynames.Role, name string) error {
 	return r.withTX(ctx, func(tx *sqlx.Tx) error {
-		_, err := tx.ExecContext(
-			ctx,
-			`
-				INSERT INTO likes (
-					role_id,
-					name_id,
-					liked_at
-				) VALUES (
-					$1,
-					$2,
-					CURRENT_TIMESTAMP
-				) ON CONFLICT (role_id, name_id) DO NOTHING
-			`,
-			role,
-			getIDForName(name),
-		)
-		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Unable to like name '%s' as role '%v'", name, role))
-		}
-		if err := r.removeDislikeFor(ctx, tx, role, name); err != nil {
-			return err
-		}
-		return nil
+		return r.withMatchEventsFor(ctx, tx, role, name, func() error {
+			return r.likeFor(ctx, tx, role, name)
+		})
 	})
 }
 
-// UndoLike removes a like for a name.
+// likeFor likes a name for the role. Liking a name the role already likes changes nothing, and isn't logged.
+func (r *Repository) likeFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string) error {
+	res, err := tx.ExecContext(
+		ctx,
+		`
+			INSERT INTO likes (
+				role_id,
+				name_id,
+				liked_at,
+				previously_disliked
+			) VALUES (
+				$1,
+				$2,
+				CURRENT_TIMESTAMP,
+				EXISTS (SELECT 1 FROM swipes WHERE role_id = $1 AND name_id = $2 AND action = $3)
+			) ON CONFLICT (role_id, name_id) DO NOTHING
+		`,
+		role,
+		getIDForName(name),
+		swipeDislike,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to like name '%s' as role '%v'", name, role))
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if err := r.logSwipeFor(ctx, tx, role, name, swipeLike); err != nil {
+		return err
+	}
+	if _, err := r.removeDislikeFor(ctx, tx, role, name); err != nil {
+		return err
+	}
+	return r.dequeueFor(ctx, tx, role, name)
+}
+
+// UndoLike removes a like for a name, putting it back in the queue.
 func (r *Repository) UndoLike(ctx context.Context, role babynames.Role, name string) error {
 	return r.withTX(ctx, func(tx *sqlx.Tx) error {
-		return r.removeLikeFor(ctx, tx, role, name)
+		return r.withMatchEventsFor(ctx, tx, role, name, func() error {
+			removed, err := r.removeLikeFor(ctx, tx, role, name)
+			if err != nil || !removed {
+				return err
+			}
+			if err := r.unlogSwipeFor(ctx, tx, role, name, swipeLike, swipeSuperlike); err != nil {
+				return err
+			}
+			return r.enqueueFor(ctx, tx, role, name)
+		})
+	})
+}
+
+// Rate gives a name a rating for the specified role. Ratings at or above the like threshold count as likes, while
+// lower ratings count as dislikes. Rating a name the role already likes only changes its rating, and isn't logged
+// as another like.
+func (r *Repository) Rate(ctx context.Context, role babynames.Role, name string, rating int) error {
+	if rating < babynames.RatingLikeThreshold {
+		_, err := r.Dislike(ctx, role, name)
+		return err
+	}
+
+	return r.withTX(ctx, func(tx *sqlx.Tx) error {
+		return r.withMatchEventsFor(ctx, tx, role, name, func() error {
+			var liked bool
+			err := tx.QueryRowxContext(
+				ctx,
+				`
+					INSERT INTO likes (
+						role_id,
+						name_id,
+						liked_at,
+						rating,
+						previously_disliked
+					) VALUES (
+						$1,
+						$2,
+						CURRENT_TIMESTAMP,
+						$3,
+						EXISTS (SELECT 1 FROM swipes WHERE role_id = $1 AND name_id = $2 AND action = $4)
+					) ON CONFLICT (role_id, name_id) DO UPDATE SET
+						rating = EXCLUDED.rating
+					RETURNING xmax = 0
+				`,
+				role,
+				getIDForName(name),
+				rating,
+				swipeDislike,
+			).Scan(&liked)
+			if err != nil {
+				return wrap(ctx, err, fmt.Sprintf("Unable to rate name '%s' as role '%v'", name, role))
+			}
+			if !liked {
+				return nil
+			}
+			if err := r.logSwipeFor(ctx, tx, role, name, swipeLike); err != nil {
+				return err
+			}
+			if _, err := r.removeDislikeFor(ctx, tx, role, name); err != nil {
+				return err
+			}
+			if err := r.dequeueFor(ctx, tx, role, name); err != nil {
+				return err
+			}
+			return nil
+		})
 	})
 }
 
 // Superlike flags a name as super-liked for the specified role.
 func (r *Repository) Superlike(ctx context.Context, role babynames.Role, name string) error {
 	return r.withTX(ctx, func(tx *sqlx.Tx) error {
-		_, err := tx.ExecContext(
-			ctx,
-			`
-				INSERT INTO likes (
-					role_id,
-					name_id,
-					liked_at,
-					superlike
-				) VALUES (
-					$1,
-					$2,
-					CURRENT_TIMESTAMP,
-					't'
-				) ON CONFLICT (role_id, name_id) DO NOTHING
-			`,
-			role,
-			getIDForName(name),
-		)
-		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Unable to superlike name '%s' as role '%v'", name, role))
-		}
-		if err := r.removeDislikeFor(ctx, tx, role, name); err != nil {
-			return err
-		}
+		return r.withMatchEventsFor(ctx, tx, role, name, func() error {
+			return r.superlikeFor(ctx, tx, role, name)
+		})
+	})
+}
 
-		// Delete any potential dislikes on this name from the other role
-		if err := r.removeDislikeFor(ctx, tx, babynames.InverseRole(role), name); err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Unable to delete any dislikes due to superlike of name '%s' by role '%v'", name, role))
-		}
+// superlikeFor superlikes a name for the role. Superliking a name the role already likes changes nothing, and
+// isn't logged.
+func (r *Repository) superlikeFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string) error {
+	res, err := tx.ExecContext(
+		ctx,
+		`
+			INSERT INTO likes (
+				role_id,
+				name_id,
+				liked_at,
+				superlike,
+				previously_disliked
+			) VALUES (
+				$1,
+				$2,
+				CURRENT_TIMESTAMP,
+				't',
+				EXISTS (SELECT 1 FROM swipes WHERE role_id = $1 AND name_id = $2 AND action = $3)
+			) ON CONFLICT (role_id, name_id) DO NOTHING
+		`,
+		role,
+		getIDForName(name),
+		swipeDislike,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to superlike name '%s' as role '%v'", name, role))
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if err := r.logSwipeFor(ctx, tx, role, name, swipeSuperlike); err != nil {
+		return err
+	}
+	if _, err := r.removeDislikeFor(ctx, tx, role, name); err != nil {
+		return err
+	}
+	if err := r.dequeueFor(ctx, tx, role, name); err != nil {
+		return err
+	}
 
-		return nil
-	})
+	// Delete any potential dislikes on this name from the other role, putting it back in their queue
+	if _, err := r.removeDislikeFor(ctx, tx, babynames.InverseRole(role), name); err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to delete any dislikes due to superlike of name '%s' by role '%v'", name, role))
+	}
+	if err := r.enqueueFor(ctx, tx, babynames.InverseRole(role), name); err != nil {
+		return err
+	}
+	return r.recordEventFor(ctx, tx, babynames.Event{Type: babynames.SuperlikeEvent, Role: role, Name: name})
 }
 
 // Dislike flags a name as disliked for the specified role, returning the number of times the role has disliked the name.
@@ -179,38 +563,11 @@ func (r *Repository) Dislike(ctx context.Context, role babynames.Role, name stri
 	var dislikeCount int
 
 	err := r.withTX(ctx, func(tx *sqlx.Tx) error {
-		row := tx.QueryRowxContext(
-			ctx,
-			`
-				INSERT INTO dislikes (
-					role_id,
-					name_id,
-					disliked_first_at,
-					disliked_last_at,
-					disliked_times
-				) VALUES (
-					$1,
-					$2,
-					CURRENT_TIMESTAMP,
-					CURRENT_TIMESTAMP,
-					1
-				) ON CONFLICT (role_id, name_id) DO UPDATE SET
-					disliked_last_at = CURRENT_TIMESTAMP,
-					disliked_times = dislikes.disliked_times + 1
-				RETURNING disliked_times
-			`,
-			role,
-			getIDForName(name),
-		)
-		if err := row.Scan(&dislikeCount); err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Unable to dislike name '%s' as role '%v'", name, role))
-		}
-
-		if err := r.removeLikeFor(ctx, tx, role, name); err != nil {
+		return r.withMatchEventsFor(ctx, tx, role, name, func() error {
+			var err error
+			dislikeCount, err = r.dislikeFor(ctx, tx, role, name)
 			return err
-		}
-
-		return nil
+		})
 	})
 	if err != nil {
 		return 0, err
@@ -219,13 +576,231 @@ func (r *Repository) Dislike(ctx context.Context, role babynames.Role, name stri
 	return dislikeCount, nil
 }
 
-// UndoDislike removes a dislike for a name.
-func (r *Repository) UndoDislike(ctx context.Context, role babynames.Role, name string) error {
-	return r.withTX(ctx, func(tx *sqlx.Tx) error {
-		return r.removeDislikeFor(ctx, tx, role, name)
-	})
-}
-
+func (r *Repository) dislikeFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string) (int, error) {
+	var dislikeCount int
+	row := tx.QueryRowxContext(
+		ctx,
+		`
+			INSERT INTO dislikes (
+				role_id,
+				name_id,
+				disliked_first_at,
+				disliked_last_at,
+				disliked_times
+			) VALUES (
+				$1,
+				$2,
+				CURRENT_TIMESTAMP,
+				CURRENT_TIMESTAMP,
+				1
+			) ON CONFLICT (role_id, name_id) DO UPDATE SET
+				disliked_last_at = CURRENT_TIMESTAMP,
+				disliked_times = dislikes.disliked_times + 1
+			RETURNING disliked_times
+		`,
+		role,
+		getIDForName(name),
+	)
+	if err := row.Scan(&dislikeCount); err != nil {
+		return 0, wrap(ctx, err, fmt.Sprintf("Unable to dislike name '%s' as role '%v'", name, role))
+	}
+	if err := r.logSwipeFor(ctx, tx, role, name, swipeDislike); err != nil {
+		return 0, err
+	}
+
+	if _, err := r.removeLikeFor(ctx, tx, role, name); err != nil {
+		return 0, err
+	}
+
+	// Names disliked too many times leave the queue, the others go to the back of it
+	if dislikeCount >= babynames.DislikesBeforeRemoved {
+		return dislikeCount, r.dequeueFor(ctx, tx, role, name)
+	}
+	return dislikeCount, r.enqueueFor(ctx, tx, role, name)
+}
+
+// ApplySwipes likes, superlikes and dislikes a batch of names for the specified role in a single transaction, so
+// either all of the swipes are applied or none of them are.
+func (r *Repository) ApplySwipes(ctx context.Context, role babynames.Role, swipes []babynames.Swipe) error {
+	return r.withTX(ctx, func(tx *sqlx.Tx) error {
+		for _, swipe := range swipes {
+			err := r.withMatchEventsFor(ctx, tx, role, swipe.Name, func() error {
+				switch swipe.Action {
+				case babynames.LikeSwipe:
+					return r.likeFor(ctx, tx, role, swipe.Name)
+				case babynames.SuperlikeSwipe:
+					return r.superlikeFor(ctx, tx, role, swipe.Name)
+				case babynames.DislikeSwipe:
+					_, err := r.dislikeFor(ctx, tx, role, swipe.Name)
+					return err
+				default:
+					return fmt.Errorf("Unknown swipe action '%s' on name '%s'", swipe.Action, swipe.Name)
+				}
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UndoDislike removes a dislike for a name, putting it back in the queue.
+func (r *Repository) UndoDislike(ctx context.Context, role babynames.Role, name string) error {
+	return r.withTX(ctx, func(tx *sqlx.Tx) error {
+		removed, err := r.removeDislikeFor(ctx, tx, role, name)
+		if err != nil || !removed {
+			return err
+		}
+		if err := r.unlogSwipeFor(ctx, tx, role, name, swipeDislike); err != nil {
+			return err
+		}
+		return r.enqueueFor(ctx, tx, role, name)
+	})
+}
+
+// Snooze puts off a name for the specified role, either by moving it further back in the queue or by hiding it
+// from the queue for a number of days. Snoozes don't count as dislikes.
+func (r *Repository) Snooze(ctx context.Context, role babynames.Role, name string, snooze babynames.Snooze) error {
+	return r.withTX(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`
+				INSERT INTO snoozes (
+					role_id,
+					name_id,
+					snoozed_times,
+					snoozed_last_at,
+					snoozed_until
+				) VALUES (
+					$1,
+					$2,
+					1,
+					CURRENT_TIMESTAMP,
+					CASE WHEN $3::int > 0 THEN CURRENT_TIMESTAMP + $3::int * INTERVAL '1 day' END
+				) ON CONFLICT (role_id, name_id) DO UPDATE SET
+					snoozed_times = snoozes.snoozed_times + 1,
+					snoozed_last_at = EXCLUDED.snoozed_last_at,
+					snoozed_until = EXCLUDED.snoozed_until
+			`,
+			role,
+			getIDForName(name),
+			snooze.Days,
+		)
+		if err != nil {
+			return wrap(ctx, err, fmt.Sprintf("Unable to snooze name '%s' as role '%v'", name, role))
+		}
+		if err := r.logSwipeFor(ctx, tx, role, name, swipeSnooze); err != nil {
+			return err
+		}
+
+		if snooze.Swipes <= 0 {
+			return nil
+		}
+		return r.deferFor(ctx, tx, role, name, snooze.Swipes)
+	})
+}
+
+// deferFor moves a name in the role's queue so it comes up again after the given number of other names.
+func (r *Repository) deferFor(ctx context.Context, tx *sqlx.Tx, role babynames.Role, name string, swipes int) error {
+	var positions []float64
+	err := tx.SelectContext(
+		ctx,
+		&positions,
+		`
+			SELECT
+				position
+			FROM
+				queue_order
+			WHERE
+				role_id = $1 AND
+				name_id <> $2
+			ORDER BY position
+			OFFSET $3
+			LIMIT 2
+		`,
+		role,
+		getIDForName(name),
+		swipes-1,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to find queue position to defer name '%s' to for role '%v'", name, role))
+	}
+
+	// If there aren't enough names left in the queue, the name simply goes to the back of it
+	if len(positions) < 2 {
+		return r.enqueueFor(ctx, tx, role, name)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`
+			UPDATE
+				queue_order
+			SET
+				position = $3
+			WHERE
+				role_id = $1 AND
+				name_id = $2
+		`,
+		role,
+		getIDForName(name),
+		(positions[0]+positions[1])/2,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to defer name '%s' for role '%v'", name, role))
+	}
+	return nil
+}
+
+// UndoSnooze brings a snoozed name back to the front of the role's queue.
+func (r *Repository) UndoSnooze(ctx context.Context, role babynames.Role, name string) error {
+	return r.withTX(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(
+			ctx,
+			`
+				UPDATE
+					snoozes
+				SET
+					snoozed_until = NULL
+				WHERE
+					role_id = $1 AND
+					name_id = $2
+			`,
+			role,
+			getIDForName(name),
+		)
+		if err != nil {
+			return wrap(ctx, err, fmt.Sprintf("Unable to remove snooze on name '%s' for role '%v'", name, role))
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		if err := r.unlogSwipeFor(ctx, tx, role, name, swipeSnooze); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+				UPDATE
+					queue_order
+				SET
+					position = (SELECT MIN(position) FROM queue_order WHERE role_id = $1) - 1
+				WHERE
+					role_id = $1 AND
+					name_id = $2
+			`,
+			role,
+			getIDForName(name),
+		)
+		if err != nil {
+			return wrap(ctx, err, fmt.Sprintf("Unable to move name '%s' to the front of the queue for role '%v'", name, role))
+		}
+		return nil
+	})
+}
+
 // GetPendingSuperlike gets any pending superlikes that requires the role's attention.
 func (r *Repository) GetPendingSuperlike(ctx context.Context, role babynames.Role) (string, error) {
 	otherRole := babynames.InverseRole(role)
@@ -251,11 +826,48 @@ func (r *Repository) GetPendingSuperlike(ctx context.Context, role babynames.Rol
 	)
 
 	if err := row.Scan(&name); err != nil && err != sql.ErrNoRows {
-		return "", errors.Wrap(err, fmt.Sprintf("Unable to retrieve pending superlike for role '%v'", role))
+		return "", wrap(ctx, err, fmt.Sprintf("Unable to retrieve pending superlike for role '%v'", role))
 	}
 	return name, nil
 }
 
+// GetPendingSuperlikes gets all pending superlikes that require the role's attention.
+func (r *Repository) GetPendingSuperlikes(ctx context.Context, role babynames.Role) ([]string, error) {
+	rows, err := r.db.QueryxContext(
+		ctx,
+		`
+			SELECT
+				name
+			FROM
+				names
+			INNER JOIN likes ON likes.role_id = $1 AND likes.superlike = 't' AND likes.name_id = names.id
+			LEFT JOIN dislikes ON dislikes.role_id = $2 AND dislikes.name_id = names.id
+			LEFT JOIN likes AS other_likes ON other_likes.role_id = $2 AND other_likes.name_id = names.id
+			WHERE
+				dislikes.name_id IS NULL AND
+				other_likes.name_id IS NULL
+			ORDER BY names.name
+		`,
+		babynames.InverseRole(role),
+		role,
+	)
+	if err != nil {
+		return nil, wrap(ctx, err, fmt.Sprintf("Unable to retrieve pending superlikes for role '%v'", role))
+	}
+	defer rows.Close()
+
+	res := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, wrap(ctx, err, fmt.Sprintf("Unable to read pending superlike for role '%v'", role))
+		}
+		res = append(res, name)
+	}
+
+	return res, nil
+}
+
 func (r *Repository) acknowledgeMatch(ctx context.Context, role babynames.Role, name string) error {
 	return r.withTX(ctx, func(tx *sqlx.Tx) error {
 		_, err := tx.ExecContext(
@@ -275,7 +887,7 @@ func (r *Repository) acknowledgeMatch(ctx context.Context, role babynames.Role,
 			getIDForName(name),
 		)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Unable to acknowledge match on name '%s' for role '%v'", name, role))
+			return wrap(ctx, err, fmt.Sprintf("Unable to acknowledge match on name '%s' for role '%v'", name, role))
 		}
 		return nil
 	})
@@ -302,7 +914,7 @@ func (r *Repository) GetAndAcknowledgeUnseenMatch(ctx context.Context, role baby
 		babynames.InverseRole(role),
 	)
 	if err := row.Scan(&name); err != nil && err != sql.ErrNoRows {
-		return "", errors.Wrap(err, fmt.Sprintf("Unable to retrieve unseen match for role '%v'", role))
+		return "", wrap(ctx, err, fmt.Sprintf("Unable to retrieve unseen match for role '%v'", role))
 	}
 
 	if name != "" {
@@ -315,32 +927,62 @@ func (r *Repository) GetAndAcknowledgeUnseenMatch(ctx context.Context, role baby
 
 // GetNextName gets the next name in the queue for the role.
 func (r *Repository) GetNextName(ctx context.Context, role babynames.Role) (string, int, error) {
-	var name string
-	var dislikes int
-	row := r.db.QueryRowxContext(
+	names, err := r.GetNextNames(ctx, role, 1)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(names) == 0 {
+		return "", 0, nil
+	}
+
+	return names[0].Name, names[0].Dislikes, nil
+}
+
+// GetNextNames gets the next n names in the shuffled queue for the role. The same names are returned in the same
+// order until they're liked or disliked.
+func (r *Repository) GetNextNames(ctx context.Context, role babynames.Role, n int) ([]babynames.QueuedName, error) {
+	rows, err := r.db.QueryxContext(
 		ctx,
 		`
 			SELECT
 				names.name,
 				COALESCE(dislikes.disliked_times, 0) as disliked_times
 			FROM
-				names
-			LEFT JOIN likes ON likes.role_id = $1 AND likes.name_id = names.id
-			LEFT JOIN dislikes ON dislikes.role_id = $1 AND dislikes.name_id = names.id
+				queue_order
+			INNER JOIN names ON names.id = queue_order.name_id
+			LEFT JOIN dislikes ON dislikes.role_id = $1 AND dislikes.name_id = queue_order.name_id
+			LEFT JOIN snoozes ON snoozes.role_id = $1 AND snoozes.name_id = queue_order.name_id
 			WHERE
-				likes.name_id IS NULL AND
-				(dislikes.name_id IS NULL OR dislikes.disliked_times < $2)
-			ORDER BY random()
-			LIMIT 1
+				queue_order.role_id = $1 AND
+				(snoozes.snoozed_until IS NULL OR snoozes.snoozed_until <= CURRENT_TIMESTAMP)
+			ORDER BY queue_order.position
+			LIMIT $2
 		`,
 		role,
-		babynames.DislikesBeforeRemoved,
+		n,
 	)
-	if err := row.Scan(&name, &dislikes); err != nil && err != sql.ErrNoRows {
-		return "", 0, errors.Wrap(err, fmt.Sprintf("Unable to retrieve next name for role '%v'", role))
+	if err != nil {
+		return nil, wrap(ctx, err, fmt.Sprintf("Unable to retrieve next names for role '%v'", role))
+	}
+	defer rows.Close()
+
+	res := []babynames.QueuedName{}
+	for rows.Next() {
+		var (
+			name     string
+			dislikes int
+		)
+		if err := rows.Scan(&name, &dislikes); err != nil {
+			return nil, wrap(ctx, err, fmt.Sprintf("Unable to read next name for role '%v'", role))
+		}
+
+		res = append(res, babynames.QueuedName{
+			Name:     name,
+			Dislikes: dislikes,
+		})
 	}
 
-	return name, dislikes, nil
+	return res, nil
 }
 
 // GetLikedNames gets a list of all liked names by the role.
@@ -351,6 +993,7 @@ func (r *Repository) GetLikedNames(ctx context.Context, role babynames.Role) ([]
 			SELECT
 				names.name,
 				likes.superlike,
+				likes.rating,
 				likes.liked_at
 			FROM
 				names
@@ -360,7 +1003,7 @@ func (r *Repository) GetLikedNames(ctx context.Context, role babynames.Role) ([]
 		role,
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("Unable to retrieve liked names for role '%v'", role))
+		return nil, wrap(ctx, err, fmt.Sprintf("Unable to retrieve liked names for role '%v'", role))
 	}
 	defer rows.Close()
 
@@ -369,15 +1012,17 @@ func (r *Repository) GetLikedNames(ctx context.Context, role babynames.Role) ([]
 		var (
 			name      string
 			superlike bool
+			rating    sql.NullInt64
 			likedAt   time.Time
 		)
-		if err := rows.Scan(&name, &superlike, &likedAt); err != nil {
-			return nil, errors.Wrap(err, fmt.Sprintf("Unable to read liked name for role '%v'", role))
+		if err := rows.Scan(&name, &superlike, &rating, &likedAt); err != nil {
+			return nil, wrap(ctx, err, fmt.Sprintf("Unable to read liked name for role '%v'", role))
 		}
 
 		res = append(res, babynames.LikedName{
 			Name:       name,
 			Superliked: superlike,
+			Rating:     int(rating.Int64),
 			LikedAt:    likedAt,
 		})
 	}
@@ -403,7 +1048,7 @@ func (r *Repository) GetDislikedNames(ctx context.Context, role babynames.Role)
 		role,
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("Unable to retrieve disliked names for role '%v'", role))
+		return nil, wrap(ctx, err, fmt.Sprintf("Unable to retrieve disliked names for role '%v'", role))
 	}
 	defer rows.Close()
 
@@ -416,7 +1061,7 @@ func (r *Repository) GetDislikedNames(ctx context.Context, role babynames.Role)
 			lastAt  time.Time
 		)
 		if err := rows.Scan(&name, &count, &firstAt, &lastAt); err != nil {
-			return nil, errors.Wrap(err, fmt.Sprintf("Unable to read disliked name for role '%v'", role))
+			return nil, wrap(ctx, err, fmt.Sprintf("Unable to read disliked name for role '%v'", role))
 		}
 
 		res = append(res, babynames.DislikedName{
@@ -430,7 +1075,53 @@ func (r *Repository) GetDislikedNames(ctx context.Context, role babynames.Role)
 	return res, nil
 }
 
-// GetMatches gets a list of all names that are matched with the other role.
+// GetSnoozedNames gets a list of all names snoozed by the role that are still in the queue.
+func (r *Repository) GetSnoozedNames(ctx context.Context, role babynames.Role) ([]babynames.SnoozedName, error) {
+	rows, err := r.db.QueryxContext(
+		ctx,
+		`
+			SELECT
+				names.name,
+				snoozes.snoozed_times,
+				snoozes.snoozed_last_at,
+				CASE WHEN snoozes.snoozed_until > CURRENT_TIMESTAMP THEN snoozes.snoozed_until END
+			FROM
+				names
+			INNER JOIN snoozes ON snoozes.role_id = $1 AND snoozes.name_id = names.id
+			INNER JOIN queue_order ON queue_order.role_id = $1 AND queue_order.name_id = names.id
+			ORDER BY snoozes.snoozed_last_at DESC
+		`,
+		role,
+	)
+	if err != nil {
+		return nil, wrap(ctx, err, fmt.Sprintf("Unable to retrieve snoozed names for role '%v'", role))
+	}
+	defer rows.Close()
+
+	res := []babynames.SnoozedName{}
+	for rows.Next() {
+		var (
+			name        string
+			count       int
+			lastSnoozed time.Time
+			until       pq.NullTime
+		)
+		if err := rows.Scan(&name, &count, &lastSnoozed, &until); err != nil {
+			return nil, wrap(ctx, err, fmt.Sprintf("Unable to read snoozed name for role '%v'", role))
+		}
+
+		res = append(res, babynames.SnoozedName{
+			Name:        name,
+			Count:       count,
+			LastSnoozed: lastSnoozed,
+			Until:       until.Time,
+		})
+	}
+
+	return res, nil
+}
+
+// GetMatches gets a list of all names that are matched with the other role, with the strongest matches first.
 func (r *Repository) GetMatches(ctx context.Context, role babynames.Role) ([]babynames.Match, error) {
 	rows, err := r.db.QueryxContext(
 		ctx,
@@ -439,8 +1130,13 @@ func (r *Repository) GetMatches(ctx context.Context, role babynames.Role) ([]bab
 				names.name,
 				role_likes.liked_at AS role_liked_at,
 				role_likes.superlike AS role_superlike,
+				role_likes.rating AS role_rating,
+				role_likes.previously_disliked AS role_previously_disliked,
 				other_role_likes.liked_at AS other_role_liked_at,
-				other_role_likes.superlike AS other_role_superlike
+				other_role_likes.superlike AS other_role_superlike,
+				other_role_likes.rating AS other_role_rating,
+				other_role_likes.previously_disliked AS other_role_previously_disliked,
+				(SELECT COUNT(1) FROM notes WHERE notes.name_id = names.id AND notes.shared) AS shared_notes
 			FROM
 				names
 			INNER JOIN likes AS role_likes ON role_likes.role_id = $1 AND role_likes.name_id = names.id
@@ -451,110 +1147,1154 @@ func (r *Repository) GetMatches(ctx context.Context, role babynames.Role) ([]bab
 		babynames.InverseRole(role),
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("Unable to retrieve matched names for role '%v'", role))
+		return nil, wrap(ctx, err, fmt.Sprintf("Unable to retrieve matched names for role '%v'", role))
 	}
 	defer rows.Close()
 
 	res := []babynames.Match{}
 	for rows.Next() {
 		var (
-			name            string
-			roleLikedAt     time.Time
-			roleSuperliked  bool
-			otherLikedAt    time.Time
-			otherSuperliked bool
+			name        string
+			roleRating  sql.NullInt64
+			otherRating sql.NullInt64
+			roleMatch   babynames.MatchRole
+			otherMatch  babynames.MatchRole
+			sharedNotes int
+		)
+		err := rows.Scan(
+			&name,
+			&roleMatch.LikedAt,
+			&roleMatch.Superliked,
+			&roleRating,
+			&roleMatch.PreviouslyDisliked,
+			&otherMatch.LikedAt,
+			&otherMatch.Superliked,
+			&otherRating,
+			&otherMatch.PreviouslyDisliked,
+			&sharedNotes,
 		)
-		if err := rows.Scan(&name, &roleLikedAt, &roleSuperliked, &otherLikedAt, &otherSuperliked); err != nil {
-			return nil, errors.Wrap(err, fmt.Sprintf("Unable to read matched name for role '%v'", role))
+		if err != nil {
+			return nil, wrap(ctx, err, fmt.Sprintf("Unable to read matched name for role '%v'", role))
 		}
+		roleMatch.Rating = int(roleRating.Int64)
+		otherMatch.Rating = int(otherRating.Int64)
 
-		res = append(res, babynames.Match{
+		match := babynames.Match{
 			Name: name,
 			Roles: map[babynames.Role]babynames.MatchRole{
-				role: babynames.MatchRole{
-					LikedAt:    roleLikedAt,
-					Superliked: roleSuperliked,
-				},
-				babynames.InverseRole(role): babynames.MatchRole{
-					LikedAt:    otherLikedAt,
-					Superliked: otherSuperliked,
-				},
+				role:                        roleMatch,
+				babynames.InverseRole(role): otherMatch,
 			},
-		})
+			SharedNotes: sharedNotes,
+		}
+		match.Score = match.StrengthScore()
+		res = append(res, match)
 	}
 
+	// Strongest matches first, alphabetically within the same score
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+
 	return res, nil
 }
 
-// GetStats retrieves the progression stats of a role.
+// IsMatch checks if a name has been liked by both roles.
+func (r *Repository) IsMatch(ctx context.Context, name string) (bool, error) {
+	return r.isMatchFor(ctx, r.db, name)
+}
+
+// GetStats retrieves the progression stats of a role. Names snoozed for some days don't count as queued until the
+// snooze is over.
 func (r *Repository) GetStats(ctx context.Context, role babynames.Role) (babynames.Stats, error) {
-	// Get total number of names
-	var total int
-	err := r.db.QueryRowxContext(ctx, "SELECT COUNT(1) FROM names").Scan(&total)
+	var stats babynames.Stats
+	err := r.db.QueryRowxContext(
+		ctx,
+		`
+			SELECT
+				(SELECT COUNT(1) FROM names),
+				(SELECT COUNT(1) FROM likes WHERE role_id = $1),
+				(SELECT COUNT(1) FROM dislikes WHERE role_id = $1),
+				(
+					SELECT
+						COUNT(1)
+					FROM
+						queue_order
+					LEFT JOIN snoozes ON snoozes.role_id = $1 AND snoozes.name_id = queue_order.name_id
+					WHERE
+						queue_order.role_id = $1 AND
+						(snoozes.snoozed_until IS NULL OR snoozes.snoozed_until <= CURRENT_TIMESTAMP)
+				),
+				(
+					SELECT
+						COUNT(1)
+					FROM
+						likes
+					INNER JOIN likes AS other_likes ON other_likes.role_id = $2 AND other_likes.name_id = likes.name_id
+					WHERE
+						likes.role_id = $1
+				)
+		`,
+		role,
+		babynames.InverseRole(role),
+	).Scan(&stats.Total, &stats.Liked, &stats.Disliked, &stats.Queued, &stats.Matched)
 	if err != nil {
-		return babynames.Stats{}, errors.Wrap(err, "Unable to count all names")
+		return babynames.Stats{}, wrap(ctx, err, fmt.Sprintf("Unable to count names for role '%v'", role))
 	}
 
-	// Get number of liked names
-	var liked int
-	err = r.db.QueryRowxContext(ctx, "SELECT COUNT(1) FROM likes WHERE role_id = $1", role).Scan(&liked)
-	if err != nil {
-		return babynames.Stats{}, errors.Wrap(err, fmt.Sprintf("Unable to count liked names for role '%v'", role))
-	}
+	return stats, nil
+}
 
-	// Get number of disliked names
-	var disliked int
-	err = r.db.QueryRowxContext(ctx, "SELECT COUNT(1) FROM dislikes WHERE role_id = $1", role).Scan(&disliked)
+// GetProgress retrieves how many names there are and how many of them are still in the queue of a role. Unlike
+// GetStats, snoozed names count as queued, so the queue order index is all that's needed to count them.
+func (r *Repository) GetProgress(ctx context.Context, role babynames.Role) (babynames.Progress, error) {
+	var progress babynames.Progress
+	err := r.db.QueryRowxContext(
+		ctx,
+		`
+			SELECT
+				(SELECT COUNT(1) FROM names),
+				(SELECT COUNT(1) FROM queue_order WHERE role_id = $1)
+		`,
+		role,
+	).Scan(&progress.Total, &progress.Queued)
 	if err != nil {
-		return babynames.Stats{}, errors.Wrap(err, fmt.Sprintf("Unable to count disliked names for role '%v'", role))
+		return babynames.Progress{}, wrap(ctx, err, fmt.Sprintf("Unable to count queued names for role '%v'", role))
 	}
 
-	// Get number of queued names (names that has not been liked, and disliked less than the required number of times for exclusion)
-	var queued int
-	err = r.db.QueryRowxContext(
+	return progress, nil
+}
+
+// CountMatches counts the names liked by both roles.
+func (r *Repository) CountMatches(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowxContext(
 		ctx,
 		`
 			SELECT
 				COUNT(1)
 			FROM
-				names
-			LEFT JOIN likes ON likes.role_id = $1 AND likes.name_id = names.id
-			LEFT JOIN dislikes ON dislikes.role_id = $1 AND dislikes.name_id = names.id AND dislikes.disliked_times >= $2 
+				likes
+			INNER JOIN likes AS other_likes ON other_likes.role_id = $2 AND other_likes.name_id = likes.name_id
 			WHERE
-				likes.name_id IS NULL AND
-				dislikes.name_id IS NULL
+				likes.role_id = $1
+		`,
+		babynames.MomRole,
+		babynames.DadRole,
+	).Scan(&count)
+	if err != nil {
+		return 0, wrap(ctx, err, "Unable to count matches")
+	}
+
+	return count, nil
+}
+
+// AddNote attaches a note to a name for the role, optionally sharing it with the other role.
+func (r *Repository) AddNote(ctx context.Context, role babynames.Role, name, text string, shared bool) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO notes (
+				role_id,
+				name_id,
+				text,
+				shared,
+				created_at
+			) VALUES (
+				$1,
+				$2,
+				$3,
+				$4,
+				CURRENT_TIMESTAMP
+			)
 		`,
 		role,
-		babynames.DislikesBeforeRemoved,
-	).Scan(&queued)
+		getIDForName(name),
+		text,
+		shared,
+	)
 	if err != nil {
-		return babynames.Stats{}, errors.Wrap(err, fmt.Sprintf("Unable to count queued names for role '%v'", role))
+		return wrap(ctx, err, fmt.Sprintf("Unable to add note on name '%s' for role '%v'", name, role))
 	}
+	return nil
+}
+
+// DeleteNote deletes one of the role's notes.
+func (r *Repository) DeleteNote(ctx context.Context, role babynames.Role, id int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM notes WHERE id = $1 AND role_id = $2", id, role)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to delete note %d for role '%v'", id, role))
+	}
+	return nil
+}
 
-	// Get number of matched names
-	var matched int
-	err = r.db.QueryRowxContext(
+// GetNotes gets a list of all notes visible to the role; its own notes and the notes shared by the other role.
+func (r *Repository) GetNotes(ctx context.Context, role babynames.Role) ([]babynames.Note, error) {
+	rows, err := r.db.QueryxContext(
 		ctx,
 		`
 			SELECT
-				COUNT(1)
+				notes.id,
+				notes.role_id,
+				names.name,
+				notes.text,
+				notes.shared,
+				notes.created_at
 			FROM
-				names
-			INNER JOIN likes ON likes.role_id = $1 AND likes.name_id = names.id
-			INNER JOIN likes AS other_likes ON other_likes.role_id = $2 AND other_likes.name_id = names.id
+				notes
+			INNER JOIN names ON names.id = notes.name_id
+			WHERE
+				notes.role_id = $1 OR
+				notes.shared
+			ORDER BY names.name, notes.created_at
 		`,
 		role,
-		babynames.InverseRole(role),
-	).Scan(&matched)
+	)
 	if err != nil {
-		return babynames.Stats{}, errors.Wrap(err, fmt.Sprintf("Unable to count matched names for role '%v'", role))
+		return nil, wrap(ctx, err, fmt.Sprintf("Unable to retrieve notes for role '%v'", role))
+	}
+	defer rows.Close()
+
+	res := []babynames.Note{}
+	for rows.Next() {
+		var note babynames.Note
+		if err := rows.Scan(&note.ID, &note.Role, &note.Name, &note.Text, &note.Shared, &note.CreatedAt); err != nil {
+			return nil, wrap(ctx, err, fmt.Sprintf("Unable to read note for role '%v'", role))
+		}
+		res = append(res, note)
 	}
 
-	return babynames.Stats{
-		Total:    total,
-		Liked:    liked,
-		Disliked: disliked,
-		Queued:   queued,
-		Matched:  matched,
-	}, nil
+	return res, nil
+}
+
+// TagName adds a personal tag to a name liked by the role. Names the role hasn't liked can't be tagged, which
+// returns babynames.ErrNotLiked.
+func (r *Repository) TagName(ctx context.Context, role babynames.Role, name, tag string) error {
+	var liked bool
+	err := r.db.QueryRowxContext(
+		ctx,
+		`
+			WITH liked AS (
+				SELECT
+					role_id,
+					name_id
+				FROM
+					likes
+				WHERE
+					role_id = $1 AND
+					name_id = $2
+			), tagged AS (
+				INSERT INTO tags (
+					role_id,
+					name_id,
+					tag,
+					tagged_at
+				)
+				SELECT
+					role_id,
+					name_id,
+					$3,
+					CURRENT_TIMESTAMP
+				FROM
+					liked
+				ON CONFLICT (role_id, name_id, tag) DO NOTHING
+			)
+			SELECT EXISTS (SELECT 1 FROM liked)
+		`,
+		role,
+		getIDForName(name),
+		tag,
+	).Scan(&liked)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to tag name '%s' with '%s' for role '%v'", name, tag, role))
+	}
+	if !liked {
+		return babynames.ErrNotLiked
+	}
+	return nil
+}
+
+// UntagName removes a personal tag from a name.
+func (r *Repository) UntagName(ctx context.Context, role babynames.Role, name, tag string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"DELETE FROM tags WHERE role_id = $1 AND name_id = $2 AND tag = $3",
+		role,
+		getIDForName(name),
+		tag,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to remove tag '%s' from name '%s' for role '%v'", tag, name, role))
+	}
+	return nil
+}
+
+// GetTags gets the role's personal tags on the names it has liked, keyed by name.
+func (r *Repository) GetTags(ctx context.Context, role babynames.Role) (map[string][]string, error) {
+	rows, err := r.db.QueryxContext(
+		ctx,
+		`
+			SELECT
+				names.name,
+				tags.tag
+			FROM
+				tags
+			INNER JOIN names ON names.id = tags.name_id
+			INNER JOIN likes ON likes.role_id = tags.role_id AND likes.name_id = tags.name_id
+			WHERE
+				tags.role_id = $1
+			ORDER BY names.name, tags.tag
+		`,
+		role,
+	)
+	if err != nil {
+		return nil, wrap(ctx, err, fmt.Sprintf("Unable to retrieve tags for role '%v'", role))
+	}
+	defer rows.Close()
+
+	res := map[string][]string{}
+	for rows.Next() {
+		var name, tag string
+		if err := rows.Scan(&name, &tag); err != nil {
+			return nil, wrap(ctx, err, fmt.Sprintf("Unable to read tag for role '%v'", role))
+		}
+		res[name] = append(res[name], tag)
+	}
+
+	return res, nil
+}
+
+// GetDailyStats gets the swiping activity of a role per day, from the first day it swiped until today.
+func (r *Repository) GetDailyStats(ctx context.Context, role babynames.Role) ([]babynames.DailyStats, error) {
+	rows, err := r.db.QueryxContext(
+		ctx,
+		`
+			SELECT
+				days.day,
+				COUNT(swipes.id) AS swiped,
+				COUNT(swipes.id) FILTER (WHERE swipes.action = $2) AS liked,
+				COUNT(swipes.id) FILTER (WHERE swipes.action = $3) AS superliked,
+				COUNT(swipes.id) FILTER (WHERE swipes.action = $4) AS disliked,
+				COUNT(swipes.id) FILTER (WHERE swipes.action = $5) AS snoozed,
+				(
+					SELECT
+						COUNT(1)
+					FROM
+						likes
+					INNER JOIN likes AS other_likes ON other_likes.role_id = $6 AND other_likes.name_id = likes.name_id
+					WHERE
+						likes.role_id = $1 AND
+						date_trunc('day', GREATEST(likes.liked_at, other_likes.liked_at)) = days.day
+				) AS matched
+			FROM
+				generate_series(
+					(SELECT date_trunc('day', MIN(swiped_at)) FROM swipes WHERE role_id = $1),
+					date_trunc('day', CURRENT_TIMESTAMP),
+					INTERVAL '1 day'
+				) AS days (day)
+			LEFT JOIN swipes ON swipes.role_id = $1 AND date_trunc('day', swipes.swiped_at) = days.day
+			GROUP BY days.day
+			ORDER BY days.day
+		`,
+		role,
+		swipeLike,
+		swipeSuperlike,
+		swipeDislike,
+		swipeSnooze,
+		babynames.InverseRole(role),
+	)
+	if err != nil {
+		return nil, wrap(ctx, err, fmt.Sprintf("Unable to retrieve daily stats for role '%v'", role))
+	}
+	defer rows.Close()
+
+	res := []babynames.DailyStats{}
+	for rows.Next() {
+		var day babynames.DailyStats
+		if err := rows.Scan(&day.Day, &day.Swiped, &day.Liked, &day.Superliked, &day.Disliked, &day.Snoozed, &day.Matched); err != nil {
+			return nil, wrap(ctx, err, fmt.Sprintf("Unable to read daily stats for role '%v'", role))
+		}
+		res = append(res, day)
+	}
+
+	return res, nil
+}
+
+// agreementVotes pairs up the votes of both roles on every name both of them have liked or disliked.
+const agreementVotes = `
+	WITH votes AS (
+		SELECT role_id, name_id, 't'::bool AS liked FROM likes
+		UNION ALL
+		SELECT role_id, name_id, 'f'::bool AS liked FROM dislikes
+	)
+	SELECT
+		role_votes.name_id,
+		role_votes.liked AS role_liked,
+		other_votes.liked AS other_liked
+	FROM
+		votes AS role_votes
+	INNER JOIN votes AS other_votes ON other_votes.role_id = $2 AND other_votes.name_id = role_votes.name_id
+	WHERE
+		role_votes.role_id = $1
+`
+
+// GetAgreement compares the votes of the two roles; how often they agree, how much more than by chance (Cohen's
+// kappa), the names they disagree on and how many of their likes turned into matches.
+func (r *Repository) GetAgreement(ctx context.Context) (babynames.Agreement, error) {
+	role := babynames.Roles[0]
+	otherRole := babynames.InverseRole(role)
+	res := babynames.Agreement{
+		OnlyLiked: map[babynames.Role]int{},
+		Likes:     map[babynames.Role]int{},
+		Opposites: []babynames.OppositeVote{},
+	}
+
+	// The votes are read in a single snapshot, so the counts add up even when votes change in between
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return babynames.Agreement{}, wrap(ctx, err, "Unable to start PostgreSQL transaction")
+	}
+	defer tx.Rollback()
+
+	// Kappa is undefined when both gave every name the same vote, as chance alone would agree on all of them.
+	// They do agree on all of them, so that counts as perfect agreement.
+	var roleOnly, otherOnly int
+	err = tx.QueryRowxContext(
+		ctx,
+		`
+			WITH pairs AS (`+agreementVotes+`),
+			counts AS (
+				SELECT
+					COUNT(1) FILTER (WHERE role_liked AND other_liked) AS both_liked,
+					COUNT(1) FILTER (WHERE role_liked AND NOT other_liked) AS role_only,
+					COUNT(1) FILTER (WHERE NOT role_liked AND other_liked) AS other_only,
+					COUNT(1) FILTER (WHERE NOT role_liked AND NOT other_liked) AS both_disliked,
+					COUNT(1) AS seen
+				FROM
+					pairs
+			),
+			rates AS (
+				SELECT
+					counts.*,
+					CASE WHEN seen = 0 THEN 0 ELSE (both_liked + both_disliked)::float / seen END AS observed,
+					CASE WHEN seen = 0 THEN 0 ELSE (
+						(both_liked + role_only)::float * (both_liked + other_only) +
+						(other_only + both_disliked)::float * (role_only + both_disliked)
+					) / (seen::float * seen) END AS expected
+				FROM
+					counts
+			)
+			SELECT
+				seen,
+				both_liked,
+				both_disliked,
+				role_only,
+				other_only,
+				observed,
+				CASE WHEN expected = 1 THEN 1 ELSE (observed - expected) / (1 - expected) END AS kappa
+			FROM
+				rates
+		`,
+		role,
+		otherRole,
+	).Scan(&res.Seen, &res.BothLiked, &res.BothDisliked, &roleOnly, &otherOnly, &res.Rate, &res.Kappa)
+	if err != nil {
+		return babynames.Agreement{}, wrap(ctx, err, "Unable to compare votes")
+	}
+	res.OnlyLiked[role] = roleOnly
+	res.OnlyLiked[otherRole] = otherOnly
+
+	rows, err := tx.QueryxContext(
+		ctx,
+		`
+			WITH pairs AS (`+agreementVotes+`)
+			SELECT
+				names.name,
+				pairs.role_liked
+			FROM
+				pairs
+			INNER JOIN names ON names.id = pairs.name_id
+			WHERE
+				pairs.role_liked <> pairs.other_liked
+			ORDER BY names.name
+		`,
+		role,
+		otherRole,
+	)
+	if err != nil {
+		return babynames.Agreement{}, wrap(ctx, err, "Unable to retrieve names with opposite votes")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name      string
+			roleLiked bool
+		)
+		if err := rows.Scan(&name, &roleLiked); err != nil {
+			return babynames.Agreement{}, wrap(ctx, err, "Unable to read name with opposite votes")
+		}
+
+		likedBy := otherRole
+		if roleLiked {
+			likedBy = role
+		}
+		res.Opposites = append(res.Opposites, babynames.OppositeVote{
+			Name:    name,
+			LikedBy: likedBy,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return babynames.Agreement{}, wrap(ctx, err, "Unable to retrieve names with opposite votes")
+	}
+
+	var roleLikes, otherLikes int
+	err = tx.QueryRowxContext(
+		ctx,
+		`
+			SELECT
+				(SELECT COUNT(1) FROM likes WHERE role_id = $1),
+				(SELECT COUNT(1) FROM likes WHERE role_id = $2),
+				(
+					SELECT
+						COUNT(1)
+					FROM
+						likes
+					INNER JOIN likes AS other_likes ON other_likes.role_id = $2 AND other_likes.name_id = likes.name_id
+					WHERE
+						likes.role_id = $1
+				)
+		`,
+		role,
+		otherRole,
+	).Scan(&roleLikes, &otherLikes, &res.Matches)
+	if err != nil {
+		return babynames.Agreement{}, wrap(ctx, err, "Unable to count likes and matches")
+	}
+	res.Likes[role] = roleLikes
+	res.Likes[otherRole] = otherLikes
+
+	return res, nil
+}
+
+// CreateAccount creates a local account with the specified password hash.
+func (r *Repository) CreateAccount(ctx context.Context, email, passwordHash string) error {
+	return createAccount(ctx, r.db, email, passwordHash)
+}
+
+// CreateInvitedAccount adds an e-mail address to the household as a member with the specified role, or as a guest,
+// and creates a local account for it in the same transaction, so a failed join doesn't leave an account behind.
+func (r *Repository) CreateInvitedAccount(ctx context.Context, email, passwordHash string, role babynames.Role, guest bool) error {
+	return r.withTX(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		if guest {
+			err = addGuest(ctx, tx, email)
+		} else {
+			err = addMember(ctx, tx, email, role)
+		}
+		if err != nil {
+			return err
+		}
+		return createAccount(ctx, tx, email, passwordHash)
+	})
+}
+
+func createAccount(ctx context.Context, db sqlx.ExecerContext, email, passwordHash string) error {
+	res, err := db.ExecContext(
+		ctx,
+		`
+			INSERT INTO accounts (
+				email,
+				password_hash,
+				created_at,
+				updated_at
+			) VALUES (
+				$1,
+				$2,
+				CURRENT_TIMESTAMP,
+				CURRENT_TIMESTAMP
+			) ON CONFLICT (email) DO NOTHING
+		`,
+		email,
+		passwordHash,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to create account for '%s'", email))
+	}
+
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return babynames.ErrAccountExists
+	}
+	return nil
+}
+
+// GetPasswordHash gets the password hash of a local account, or an empty string if the account does not exist.
+func (r *Repository) GetPasswordHash(ctx context.Context, email string) (string, error) {
+	var hash string
+	row := r.db.QueryRowxContext(ctx, "SELECT password_hash FROM accounts WHERE email = $1", email)
+	if err := row.Scan(&hash); err != nil && err != sql.ErrNoRows {
+		return "", wrap(ctx, err, fmt.Sprintf("Unable to retrieve password hash for '%s'", email))
+	}
+	return hash, nil
+}
+
+// SetPasswordHash sets the password hash of a local account, creating the account if it does not exist.
+func (r *Repository) SetPasswordHash(ctx context.Context, email, passwordHash string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO accounts (
+				email,
+				password_hash,
+				created_at,
+				updated_at
+			) VALUES (
+				$1,
+				$2,
+				CURRENT_TIMESTAMP,
+				CURRENT_TIMESTAMP
+			) ON CONFLICT (email) DO UPDATE SET
+				password_hash = EXCLUDED.password_hash,
+				updated_at = CURRENT_TIMESTAMP
+		`,
+		email,
+		passwordHash,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to set password for '%s'", email))
+	}
+	return nil
+}
+
+// CreateLoginToken stores a one-time login token for an e-mail address.
+func (r *Repository) CreateLoginToken(ctx context.Context, email string, purpose babynames.LoginTokenPurpose, tokenHash string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO login_tokens (
+				token_hash,
+				email,
+				purpose,
+				expires_at
+			) VALUES (
+				$1,
+				$2,
+				$3,
+				$4
+			)
+		`,
+		tokenHash,
+		email,
+		purpose,
+		expiresAt,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to create %s token for '%s'", purpose, email))
+	}
+	return nil
+}
+
+// ConsumeLoginToken flags a one-time login token as used, returning the e-mail address it was issued to. An empty
+// string is returned if the token does not exist, has expired or has already been used.
+func (r *Repository) ConsumeLoginToken(ctx context.Context, purpose babynames.LoginTokenPurpose, tokenHash string) (string, error) {
+	var email string
+	row := r.db.QueryRowxContext(
+		ctx,
+		`
+			UPDATE
+				login_tokens
+			SET
+				used_at = CURRENT_TIMESTAMP
+			WHERE
+				token_hash = $1 AND
+				purpose = $2 AND
+				used_at IS NULL AND
+				expires_at > CURRENT_TIMESTAMP
+			RETURNING email
+		`,
+		tokenHash,
+		purpose,
+	)
+	if err := row.Scan(&email); err != nil && err != sql.ErrNoRows {
+		return "", wrap(ctx, err, fmt.Sprintf("Unable to consume %s token", purpose))
+	}
+	return email, nil
+}
+
+// GetRoleForEmail gets the role of a household member, returning babynames.ErrNotMember if the e-mail address
+// has not joined the household.
+func (r *Repository) GetRoleForEmail(ctx context.Context, email string) (babynames.Role, error) {
+	var role babynames.Role
+	row := r.db.QueryRowxContext(ctx, "SELECT role_id FROM members WHERE email = $1", email)
+	if err := row.Scan(&role); err != nil {
+		if err == sql.ErrNoRows {
+			return babynames.MomRole, babynames.ErrNotMember
+		}
+		return babynames.MomRole, wrap(ctx, err, fmt.Sprintf("Unable to retrieve role for '%s'", email))
+	}
+	return role, nil
+}
+
+// AddMember adds an e-mail address to the household with the specified role, returning babynames.ErrMemberExists
+// if either the e-mail address or the role is already taken.
+func (r *Repository) AddMember(ctx context.Context, email string, role babynames.Role) error {
+	return addMember(ctx, r.db, email, role)
+}
+
+func addMember(ctx context.Context, db sqlx.ExecerContext, email string, role babynames.Role) error {
+	res, err := db.ExecContext(
+		ctx,
+		`
+			INSERT INTO members (
+				email,
+				role_id,
+				joined_at
+			) VALUES (
+				$1,
+				$2,
+				CURRENT_TIMESTAMP
+			) ON CONFLICT DO NOTHING
+		`,
+		email,
+		role,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to add '%s' to the household as role '%v'", email, role))
+	}
+
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return babynames.ErrMemberExists
+	}
+	return nil
+}
+
+// GetMembers gets a list of all members of the household.
+func (r *Repository) GetMembers(ctx context.Context) ([]babynames.Member, error) {
+	rows, err := r.db.QueryxContext(ctx, "SELECT email, role_id, joined_at FROM members ORDER BY role_id")
+	if err != nil {
+		return nil, wrap(ctx, err, "Unable to retrieve household members")
+	}
+	defer rows.Close()
+
+	res := []babynames.Member{}
+	for rows.Next() {
+		var member babynames.Member
+		if err := rows.Scan(&member.Email, &member.Role, &member.JoinedAt); err != nil {
+			return nil, wrap(ctx, err, "Unable to read household member")
+		}
+		res = append(res, member)
+	}
+
+	return res, nil
+}
+
+// AddGuest adds an e-mail address as a guest voter, if it isn't one already.
+func (r *Repository) AddGuest(ctx context.Context, email string) error {
+	return addGuest(ctx, r.db, email)
+}
+
+func addGuest(ctx context.Context, db sqlx.ExecerContext, email string) error {
+	_, err := db.ExecContext(
+		ctx,
+		`
+			INSERT INTO guests (
+				email,
+				joined_at
+			) VALUES (
+				$1,
+				CURRENT_TIMESTAMP
+			) ON CONFLICT (email) DO NOTHING
+		`,
+		email,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to add '%s' as a guest", email))
+	}
+	return nil
+}
+
+// IsGuest checks if an e-mail address is a guest voter.
+func (r *Repository) IsGuest(ctx context.Context, email string) (bool, error) {
+	var guest bool
+	row := r.db.QueryRowxContext(ctx, "SELECT EXISTS (SELECT 1 FROM guests WHERE email = $1)", email)
+	if err := row.Scan(&guest); err != nil {
+		return false, wrap(ctx, err, fmt.Sprintf("Unable to check if '%s' is a guest", email))
+	}
+	return guest, nil
+}
+
+// GetGuests gets a list of all guest voters.
+func (r *Repository) GetGuests(ctx context.Context) ([]babynames.Guest, error) {
+	rows, err := r.db.QueryxContext(ctx, "SELECT email, joined_at FROM guests ORDER BY email")
+	if err != nil {
+		return nil, wrap(ctx, err, "Unable to retrieve guests")
+	}
+	defer rows.Close()
+
+	res := []babynames.Guest{}
+	for rows.Next() {
+		var guest babynames.Guest
+		if err := rows.Scan(&guest.Email, &guest.JoinedAt); err != nil {
+			return nil, wrap(ctx, err, "Unable to read guest")
+		}
+		res = append(res, guest)
+	}
+
+	return res, nil
+}
+
+// GuestVote records the vote of a guest on a name, replacing any previous vote by the guest.
+func (r *Repository) GuestVote(ctx context.Context, email, name string, vote babynames.GuestVote) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO guest_votes (
+				email,
+				name_id,
+				vote,
+				voted_at
+			) VALUES (
+				$1,
+				$2,
+				$3,
+				CURRENT_TIMESTAMP
+			) ON CONFLICT (email, name_id) DO UPDATE SET
+				vote = EXCLUDED.vote,
+				voted_at = CURRENT_TIMESTAMP
+		`,
+		email,
+		getIDForName(name),
+		vote,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to %s name '%s' as guest '%s'", vote, name, email))
+	}
+	return nil
+}
+
+// GetNextGuestName gets the next name in the queue for a guest.
+func (r *Repository) GetNextGuestName(ctx context.Context, email string) (string, error) {
+	var name string
+	row := r.db.QueryRowxContext(
+		ctx,
+		`
+			SELECT
+				names.name
+			FROM
+				guest_queue_order
+			INNER JOIN names ON names.id = guest_queue_order.name_id
+			LEFT JOIN guest_votes ON guest_votes.email = $1 AND guest_votes.name_id = guest_queue_order.name_id
+			WHERE
+				guest_votes.name_id IS NULL
+			ORDER BY guest_queue_order.position
+			LIMIT 1
+		`,
+		email,
+	)
+	if err := row.Scan(&name); err != nil && err != sql.ErrNoRows {
+		return "", wrap(ctx, err, fmt.Sprintf("Unable to retrieve next name for guest '%s'", email))
+	}
+
+	return name, nil
+}
+
+// GetGuestOpinions gets the aggregated guest votes of all names guests have voted on, keyed by name.
+func (r *Repository) GetGuestOpinions(ctx context.Context) (map[string]babynames.GuestOpinion, error) {
+	rows, err := r.db.QueryxContext(
+		ctx,
+		`
+			SELECT
+				names.name,
+				COUNT(1) FILTER (WHERE guest_votes.vote = $1) AS likes,
+				COUNT(1) FILTER (WHERE guest_votes.vote = $2) AS superlikes,
+				COUNT(1) FILTER (WHERE guest_votes.vote = $3) AS dislikes
+			FROM
+				names
+			INNER JOIN guest_votes ON guest_votes.name_id = names.id
+			GROUP BY names.name
+		`,
+		babynames.GuestLike,
+		babynames.GuestSuperlike,
+		babynames.GuestDislike,
+	)
+	if err != nil {
+		return nil, wrap(ctx, err, "Unable to retrieve guest opinions")
+	}
+	defer rows.Close()
+
+	res := map[string]babynames.GuestOpinion{}
+	for rows.Next() {
+		var (
+			name    string
+			opinion babynames.GuestOpinion
+		)
+		if err := rows.Scan(&name, &opinion.Likes, &opinion.Superlikes, &opinion.Dislikes); err != nil {
+			return nil, wrap(ctx, err, "Unable to read guest opinion")
+		}
+		res[name] = opinion
+	}
+
+	return res, nil
+}
+
+// GetDigestSettings gets the e-mail digest settings of a role.
+func (r *Repository) GetDigestSettings(ctx context.Context, role babynames.Role) (babynames.DigestSettings, error) {
+	var (
+		frequency  string
+		lastSentAt pq.NullTime
+	)
+	row := r.db.QueryRowxContext(ctx, "SELECT frequency, last_sent_at FROM digest_settings WHERE role_id = $1", role)
+	if err := row.Scan(&frequency, &lastSentAt); err != nil {
+		if err == sql.ErrNoRows {
+			return babynames.DigestSettings{Frequency: babynames.DefaultDigestFrequency}, nil
+		}
+		return babynames.DigestSettings{}, wrap(ctx, err, fmt.Sprintf("Unable to retrieve digest settings for role '%v'", role))
+	}
+
+	return babynames.DigestSettings{
+		Frequency:  babynames.DigestFrequency(frequency),
+		LastSentAt: lastSentAt.Time,
+	}, nil
+}
+
+// SetDigestFrequency sets how often a role receives the e-mail digest.
+func (r *Repository) SetDigestFrequency(ctx context.Context, role babynames.Role, frequency babynames.DigestFrequency) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO digest_settings (
+				role_id,
+				frequency
+			) VALUES (
+				$1,
+				$2
+			) ON CONFLICT (role_id) DO UPDATE SET
+				frequency = EXCLUDED.frequency
+		`,
+		role,
+		frequency,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to set digest frequency for role '%v'", role))
+	}
+	return nil
+}
+
+// MarkDigestSent records when the e-mail digest was last sent to a role.
+func (r *Repository) MarkDigestSent(ctx context.Context, role babynames.Role, sentAt time.Time) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO digest_settings (
+				role_id,
+				frequency,
+				last_sent_at
+			) VALUES (
+				$1,
+				$2,
+				$3
+			) ON CONFLICT (role_id) DO UPDATE SET
+				last_sent_at = EXCLUDED.last_sent_at
+		`,
+		role,
+		babynames.DefaultDigestFrequency,
+		sentAt,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to mark digest as sent for role '%v'", role))
+	}
+	return nil
+}
+
+// GetRatingMode checks if a role rates names from 1 to 5 instead of liking them.
+func (r *Repository) GetRatingMode(ctx context.Context, role babynames.Role) (bool, error) {
+	var ratingMode bool
+	row := r.db.QueryRowxContext(ctx, "SELECT rating_mode FROM role_settings WHERE role_id = $1", role)
+	if err := row.Scan(&ratingMode); err != nil && err != sql.ErrNoRows {
+		return false, wrap(ctx, err, fmt.Sprintf("Unable to retrieve rating mode for role '%v'", role))
+	}
+	return ratingMode, nil
+}
+
+// SetRatingMode sets if a role rates names from 1 to 5 instead of liking them.
+func (r *Repository) SetRatingMode(ctx context.Context, role babynames.Role, ratingMode bool) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO role_settings (
+				role_id,
+				rating_mode
+			) VALUES (
+				$1,
+				$2
+			) ON CONFLICT (role_id) DO UPDATE SET
+				rating_mode = EXCLUDED.rating_mode
+		`,
+		role,
+		ratingMode,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to set rating mode for role '%v'", role))
+	}
+	return nil
+}
+
+// GetWebhooks gets a list of all outgoing webhooks.
+func (r *Repository) GetWebhooks(ctx context.Context) ([]babynames.Webhook, error) {
+	rows, err := r.db.QueryxContext(ctx, "SELECT id, url, secret, created_at FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, wrap(ctx, err, "Unable to retrieve webhooks")
+	}
+	defer rows.Close()
+
+	res := []babynames.Webhook{}
+	for rows.Next() {
+		var webhook babynames.Webhook
+		if err := rows.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &webhook.CreatedAt); err != nil {
+			return nil, wrap(ctx, err, "Unable to read webhook")
+		}
+		res = append(res, webhook)
+	}
+
+	return res, nil
+}
+
+// AddWebhook adds an outgoing webhook, signing its payloads with secret.
+func (r *Repository) AddWebhook(ctx context.Context, url, secret string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO webhooks (
+				url,
+				secret,
+				created_at
+			) VALUES (
+				$1,
+				$2,
+				CURRENT_TIMESTAMP
+			)
+		`,
+		url,
+		secret,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to add webhook '%s'", url))
+	}
+	return nil
+}
+
+// DeleteWebhook deletes an outgoing webhook along with its delivery log.
+func (r *Repository) DeleteWebhook(ctx context.Context, id int) error {
+	return r.withTX(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE webhook_id = $1", id); err != nil {
+			return wrap(ctx, err, fmt.Sprintf("Unable to delete delivery log of webhook %d", id))
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1", id); err != nil {
+			return wrap(ctx, err, fmt.Sprintf("Unable to delete webhook %d", id))
+		}
+		return nil
+	})
+}
+
+// LogWebhookDelivery records an attempt at delivering an event to a webhook.
+func (r *Repository) LogWebhookDelivery(ctx context.Context, delivery babynames.WebhookDelivery) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`
+			INSERT INTO webhook_deliveries (
+				webhook_id,
+				event_type,
+				payload,
+				attempt,
+				status_code,
+				error,
+				succeeded,
+				attempted_at
+			) VALUES (
+				$1,
+				$2,
+				$3,
+				$4,
+				$5,
+				$6,
+				$7,
+				$8
+			)
+		`,
+		delivery.WebhookID,
+		delivery.EventType,
+		delivery.Payload,
+		delivery.Attempt,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.Succeeded,
+		delivery.AttemptedAt,
+	)
+	if err != nil {
+		return wrap(ctx, err, fmt.Sprintf("Unable to log delivery to webhook %d", delivery.WebhookID))
+	}
+	return nil
+}
+
+// GetWebhookDeliveries gets the most recent webhook delivery attempts, newest first.
+func (r *Repository) GetWebhookDeliveries(ctx context.Context, limit int) ([]babynames.WebhookDelivery, error) {
+	rows, err := r.db.QueryxContext(
+		ctx,
+		`
+			SELECT
+				webhook_deliveries.webhook_id,
+				webhooks.url,
+				webhook_deliveries.event_type,
+				webhook_deliveries.payload,
+				webhook_deliveries.attempt,
+				webhook_deliveries.status_code,
+				webhook_deliveries.error,
+				webhook_deliveries.succeeded,
+				webhook_deliveries.attempted_at
+			FROM
+				webhook_deliveries
+			INNER JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+			ORDER BY webhook_deliveries.attempted_at DESC, webhook_deliveries.id DESC
+			LIMIT $1
+		`,
+		limit,
+	)
+	if err != nil {
+		return nil, wrap(ctx, err, "Unable to retrieve webhook deliveries")
+	}
+	defer rows.Close()
+
+	res := []babynames.WebhookDelivery{}
+	for rows.Next() {
+		var (
+			delivery  babynames.WebhookDelivery
+			eventType string
+		)
+		err := rows.Scan(
+			&delivery.WebhookID,
+			&delivery.URL,
+			&eventType,
+			&delivery.Payload,
+			&delivery.Attempt,
+			&delivery.StatusCode,
+			&delivery.Error,
+			&delivery.Succeeded,
+			&delivery.AttemptedAt,
+		)
+		if err != nil {
+			return nil, wrap(ctx, err, "Unable to read webhook delivery")
+		}
+		delivery.EventType = babynames.EventType(eventType)
+		res = append(res, delivery)
+	}
+
+	return res, nil
+}
+
+// PruneWebhookDeliveries removes the webhook delivery attempts made before the given time.
+func (r *Repository) PruneWebhookDeliveries(ctx context.Context, before time.Time) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE attempted_at < $1", before); err != nil {
+		return wrap(ctx, err, "Unable to prune webhook deliveries")
+	}
+	return nil
 }
@@ -0,0 +1,15 @@
+package babynames
+
+const (
+	// MinRating is the lowest rating a name can be given in rating mode
+	MinRating = 1
+
+	// MaxRating is the highest rating a name can be given in rating mode
+	MaxRating = 5
+
+	// RatingLikeThreshold is the lowest rating that counts as a like; lower ratings count as dislikes
+	RatingLikeThreshold = 3
+)
+
+// Ratings lists all the ratings a name can be given, from lowest to highest.
+var Ratings = []int{1, 2, 3, 4, 5}
@@ -13,6 +13,9 @@ const (
 	DadRole
 )
 
+// Roles lists all the roles in a household.
+var Roles = []Role{MomRole, DadRole}
+
 // InverseRole gets the inverse of the specified role (mom turns in to dad, and vice versa)
 func InverseRole(role Role) Role {
 	switch role {
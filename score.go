@@ -0,0 +1,100 @@
+package babynames
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScoreFactor describes one part of a match's strength score.
+type ScoreFactor struct {
+	Description string
+	Points      int
+}
+
+const (
+	// quickAgreementPoints is awarded when the second like on a name came within quickAgreementWindow of the first,
+	// and agreementPoints when it came within agreementWindow
+	quickAgreementPoints = 2
+	quickAgreementWindow = 24 * time.Hour
+	agreementPoints      = 1
+	agreementWindow      = 7 * 24 * time.Hour
+
+	// previouslyDislikedPoints is awarded for each role that disliked a name before liking it
+	previouslyDislikedPoints = -2
+
+	// sharedNotePoints is awarded for each shared note on a name, up to maxSharedNotePoints
+	sharedNotePoints    = 1
+	maxSharedNotePoints = 2
+)
+
+// ScoreFactors explains the strength of a match. Each role contributes its rating or how it liked the name and a
+// penalty for having disliked it before, liking it soon after one another adds a bonus, and so do shared notes.
+func (m Match) ScoreFactors() []ScoreFactor {
+	factors := []ScoreFactor{}
+	for _, role := range Roles {
+		mr, ok := m.Roles[role]
+		if !ok {
+			continue
+		}
+		who := RoleName(role)
+
+		switch {
+		case mr.Rating > 0:
+			factors = append(factors, ScoreFactor{fmt.Sprintf("%s rated it %d stars", who, mr.Rating), mr.Score()})
+		case mr.Superliked:
+			factors = append(factors, ScoreFactor{fmt.Sprintf("%s superliked it", who), mr.Score()})
+		default:
+			factors = append(factors, ScoreFactor{fmt.Sprintf("%s liked it", who), mr.Score()})
+		}
+
+		if mr.PreviouslyDisliked {
+			factors = append(factors, ScoreFactor{fmt.Sprintf("%s disliked it before", who), previouslyDislikedPoints})
+		}
+	}
+
+	if gap, ok := m.agreementGap(); ok {
+		switch {
+		case gap <= quickAgreementWindow:
+			factors = append(factors, ScoreFactor{"Liked by both within a day", quickAgreementPoints})
+		case gap <= agreementWindow:
+			factors = append(factors, ScoreFactor{"Liked by both within a week", agreementPoints})
+		}
+	}
+
+	if m.SharedNotes > 0 {
+		points := m.SharedNotes * sharedNotePoints
+		if points > maxSharedNotePoints {
+			points = maxSharedNotePoints
+		}
+		factors = append(factors, ScoreFactor{fmt.Sprintf("%d shared notes", m.SharedNotes), points})
+	}
+
+	return factors
+}
+
+// agreementGap returns the time between the first and the second like on the name, if both roles have liked it.
+func (m Match) agreementGap() (time.Duration, bool) {
+	mom, ok := m.Roles[MomRole]
+	if !ok {
+		return 0, false
+	}
+	dad, ok := m.Roles[DadRole]
+	if !ok {
+		return 0, false
+	}
+
+	gap := mom.LikedAt.Sub(dad.LikedAt)
+	if gap < 0 {
+		gap = -gap
+	}
+	return gap, true
+}
+
+// StrengthScore sums up the score factors of a match.
+func (m Match) StrengthScore() int {
+	score := 0
+	for _, factor := range m.ScoreFactors() {
+		score += factor.Points
+	}
+	return score
+}
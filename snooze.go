@@ -0,0 +1,18 @@
+package babynames
+
+import "time"
+
+// Snooze describes how long a name should be put off for. Names snoozed for a number of swipes are moved further
+// back in the queue, while names snoozed for a number of days are hidden from the queue until then.
+type Snooze struct {
+	Swipes int
+	Days   int
+}
+
+// SnoozedName describes a name that has been snoozed and is still in the queue.
+type SnoozedName struct {
+	Name        string
+	Count       int
+	LastSnoozed time.Time
+	Until       time.Time
+}
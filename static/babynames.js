@@ -0,0 +1,205 @@
+(function () {
+  'use strict';
+
+  // Shows a dismissable notification at the top of the page.
+  function notify(html) {
+    var container = document.querySelector('.babynames-notifications');
+    if (!container) {
+      return;
+    }
+
+    var alert = document.createElement('div');
+    alert.className = 'alert alert-success alert-dismissible fade show';
+    alert.setAttribute('role', 'alert');
+    alert.innerHTML = html + '<button type="button" class="close" data-dismiss="alert" aria-label="Close"><span aria-hidden="true">&times;</span></button>';
+    container.appendChild(alert);
+  }
+
+  function escape(text) {
+    var div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+  }
+
+  // Live notifications of the partner's matches and superlikes
+  function listenForEvents() {
+    if (!window.EventSource) {
+      return;
+    }
+
+    var source = new EventSource('/events');
+    source.addEventListener('match_created', function (e) {
+      var event = JSON.parse(e.data);
+      notify('<strong>It\'s a match!</strong> ' + escape(event.who) + ' just liked <strong>' + escape(event.name) + '</strong> too.');
+    });
+    source.addEventListener('superlike', function (e) {
+      var event = JSON.parse(e.data);
+      notify('<i class="fas fa-star"></i> ' + escape(event.who) + ' superliked <strong>' + escape(event.name) + '</strong>.');
+    });
+  }
+
+  // Swiping through the queue without full page reloads. The forms on the queue cards are posted in the
+  // background, and the response is the next card which is swapped in place.
+  var swipeActions = {
+    like: '/like',
+    superlike: '/superlike',
+    dislike: '/dislike',
+    snooze: '/snooze',
+    rate: '/rate'
+  };
+  var swiping = false;
+
+  function swapCard(html, direction) {
+    var container = document.querySelector('.babynames-container');
+    container.classList.remove('babynames-swipe-like', 'babynames-swipe-superlike', 'babynames-swipe-dislike', 'babynames-swipe-snooze');
+    container.innerHTML = html;
+    if (direction) {
+      container.classList.add('babynames-swipe-' + direction);
+    }
+  }
+
+  function request(method, url, body, direction) {
+    if (swiping) {
+      return;
+    }
+    swiping = true;
+
+    fetch(url, {
+      method: method,
+      body: body,
+      credentials: 'same-origin',
+      headers: { 'X-Requested-With': 'XMLHttpRequest' }
+    }).then(function (response) {
+      if (!response.ok) {
+        throw new Error('Unexpected status ' + response.status);
+      }
+      return response.text();
+    }).then(function (html) {
+      swapCard(html, direction);
+    }).catch(function () {
+      // Fall back to a regular page load if anything goes wrong
+      window.location.href = '/';
+    }).then(function () {
+      swiping = false;
+    });
+  }
+
+  function findForm(action) {
+    return document.querySelector('.babynames-container form[action="' + swipeActions[action] + '"]');
+  }
+
+  function submit(form, action) {
+    request('POST', form.getAttribute('action'), new FormData(form), action);
+  }
+
+  function rate(rating) {
+    var input = document.querySelector('.babynames-container form[action="/rate"] input[name="rating"][value="' + rating + '"]');
+    if (input) {
+      submit(input.form, rating >= 3 ? 'like' : 'dislike');
+    }
+  }
+
+  function swipe(action) {
+    var form = findForm(action);
+    if (form) {
+      submit(form, action);
+    }
+  }
+
+  function enableSwiping() {
+    var container = document.querySelector('.babynames-container');
+    if (!container || !window.fetch || !window.FormData) {
+      return;
+    }
+
+    container.addEventListener('submit', function (e) {
+      var action = e.target.getAttribute('action');
+      for (var name in swipeActions) {
+        if (swipeActions[name] === action) {
+          e.preventDefault();
+          submit(e.target, name);
+          return;
+        }
+      }
+    });
+
+    container.addEventListener('click', function (e) {
+      var link = e.target.closest('a[href="/"]');
+      if (link) {
+        e.preventDefault();
+        request('GET', '/', null, null);
+      }
+    });
+
+    // Keyboard shortcuts: right/L likes, left/H dislikes, up/S superlikes, down/Z snoozes
+    document.addEventListener('keydown', function (e) {
+      if (e.target.tagName === 'INPUT' || e.target.tagName === 'TEXTAREA' || e.target.tagName === 'SELECT') {
+        return;
+      }
+      if (e.ctrlKey || e.metaKey || e.altKey) {
+        return;
+      }
+      switch (e.key) {
+        case 'ArrowRight':
+        case 'l':
+          swipe('like');
+          break;
+        case 'ArrowLeft':
+        case 'h':
+          swipe('dislike');
+          break;
+        case 'ArrowUp':
+        case 's':
+          swipe('superlike');
+          break;
+        case '1':
+        case '2':
+        case '3':
+        case '4':
+        case '5':
+          rate(parseInt(e.key, 10));
+          break;
+        case 'ArrowDown':
+        case 'z':
+          swipe('snooze');
+          break;
+        default:
+          return;
+      }
+      e.preventDefault();
+    });
+
+    // Touch gestures: swipe right to like, left to dislike, up to superlike and down to snooze
+    var startX = null;
+    var startY = null;
+    var threshold = 80;
+    container.addEventListener('touchstart', function (e) {
+      startX = e.changedTouches[0].clientX;
+      startY = e.changedTouches[0].clientY;
+    }, { passive: true });
+    container.addEventListener('touchend', function (e) {
+      if (startX === null) {
+        return;
+      }
+      var dx = e.changedTouches[0].clientX - startX;
+      var dy = e.changedTouches[0].clientY - startY;
+      startX = null;
+      startY = null;
+
+      if (Math.abs(dx) > Math.abs(dy)) {
+        if (dx > threshold) {
+          swipe('like');
+        } else if (dx < -threshold) {
+          swipe('dislike');
+        }
+      } else if (dy < -threshold) {
+        swipe('superlike');
+      } else if (dy > threshold) {
+        swipe('snooze');
+      }
+    }, { passive: true });
+  }
+
+  listenForEvents();
+  enableSwiping();
+})();
\ No newline at end of file
@@ -0,0 +1,26 @@
+package babynames
+
+// SwipeAction identifies what a role did with a name in a batch of swipes.
+type SwipeAction string
+
+const (
+	// LikeSwipe likes the name
+	LikeSwipe SwipeAction = "like"
+
+	// SuperlikeSwipe superlikes the name
+	SuperlikeSwipe SwipeAction = "superlike"
+
+	// DislikeSwipe dislikes the name
+	DislikeSwipe SwipeAction = "dislike"
+)
+
+// Swipe describes a single like, superlike or dislike of a name, for applying several of them at once.
+type Swipe struct {
+	Name   string
+	Action SwipeAction
+}
+
+// Valid returns whether the swipe names a known action.
+func (s Swipe) Valid() bool {
+	return s.Action == LikeSwipe || s.Action == SuperlikeSwipe || s.Action == DislikeSwipe
+}
@@ -0,0 +1,24 @@
+package babynames
+
+import "time"
+
+// Webhook describes an outgoing webhook receiving all published events.
+type Webhook struct {
+	ID        int
+	URL       string
+	Secret    string
+	CreatedAt time.Time
+}
+
+// WebhookDelivery describes a single attempt at delivering an event to a webhook.
+type WebhookDelivery struct {
+	WebhookID   int
+	URL         string
+	EventType   EventType
+	Payload     string
+	Attempt     int
+	StatusCode  int
+	Error       string
+	Succeeded   bool
+	AttemptedAt time.Time
+}
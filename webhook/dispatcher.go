@@ -0,0 +1,259 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/tanordheim/babyname-tinder"
+)
+
+const (
+	// maxAttempts is the number of times delivery of an event is attempted before giving up
+	maxAttempts = 5
+
+	// initialBackoff is the time to wait before the first retry, doubling for every retry after it
+	initialBackoff = 5 * time.Second
+
+	// requestTimeout is how long a webhook has to respond to a delivery
+	requestTimeout = 10 * time.Second
+
+	// cursorName is the name the dispatcher keeps track of the events it has dispatched under
+	cursorName = "webhooks"
+
+	// pollInterval is how often the dispatcher checks for newly recorded events
+	pollInterval = time.Second
+
+	// pollBatchSize is the number of recorded events read at a time
+	pollBatchSize = 100
+
+	// eventRetention is how long dispatched events are kept before they are pruned
+	eventRetention = 7 * 24 * time.Hour
+
+	// deliveryRetention is how long delivery attempts are kept in the delivery log before they are pruned
+	deliveryRetention = 30 * 24 * time.Hour
+
+	// pruneInterval is how often old events and delivery attempts are pruned
+	pruneInterval = time.Hour
+
+	// SignatureTolerance is how far the timestamp of a delivery may be from the time it's received for the
+	// receiver to accept it, which keeps captured deliveries from being replayed later on
+	SignatureTolerance = 5 * time.Minute
+)
+
+// Dispatcher delivers recorded events to all outgoing webhooks as signed JSON payloads. It keeps track of the
+// last event it dispatched in the database, and only moves past an event once every webhook has received it or
+// run out of attempts, so no event is skipped, even across restarts. Events that were being delivered when the
+// dispatcher stopped are delivered again when it starts.
+type Dispatcher struct {
+	repo   babynames.Repository
+	client *http.Client
+}
+
+type payload struct {
+	Event      babynames.EventType `json:"event"`
+	Role       string              `json:"role,omitempty"`
+	Name       string              `json:"name,omitempty"`
+	Count      int                 `json:"count,omitempty"`
+	OccurredAt time.Time           `json:"occurred_at"`
+}
+
+// NewDispatcher creates a new dispatcher delivering the events recorded in repo.
+func NewDispatcher(repo babynames.Repository) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Sign returns the signature of a payload sent at the given Unix timestamp, sent in the X-Babynames-Signature
+// header so receivers can verify it came from us. The timestamp is sent in the X-Babynames-Timestamp header, and
+// is signed along with the body so it can't be changed to replay the payload.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Verify checks the signature of a payload received at now, rejecting payloads signed with another secret or
+// sent more than SignatureTolerance away from now.
+func Verify(secret string, timestamp int64, signature string, body []byte, now time.Time) bool {
+	sentAt := time.Unix(timestamp, 0)
+	if sentAt.Before(now.Add(-SignatureTolerance)) || sentAt.After(now.Add(SignatureTolerance)) {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// Run delivers events as they are recorded, until the context is cancelled. Events that can't be dispatched are
+// retried on the next poll rather than skipped. Deliveries run as part of Run, so it doesn't return until they
+// have stopped.
+func (d *Dispatcher) Run(ctx context.Context) {
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+
+	cursor, err := d.repo.GetEventCursor(ctx, cursorName)
+	for err != nil {
+		logrus.WithError(err).Error("Unable to get the last dispatched event")
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+		}
+		cursor, err = d.repo.GetEventCursor(ctx, cursorName)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-prune.C:
+			if err := d.repo.PruneEvents(ctx, time.Now().Add(-eventRetention)); err != nil {
+				logrus.WithError(err).Error("Unable to prune dispatched events")
+			}
+			if err := d.repo.PruneWebhookDeliveries(ctx, time.Now().Add(-deliveryRetention)); err != nil {
+				logrus.WithError(err).Error("Unable to prune webhook deliveries")
+			}
+		case <-poll.C:
+			cursor = d.dispatchRecorded(ctx, cursor)
+		}
+	}
+}
+
+// dispatchRecorded dispatches the events recorded after cursor, returning the ID of the last one dispatched.
+func (d *Dispatcher) dispatchRecorded(ctx context.Context, cursor int64) int64 {
+	events, err := d.repo.GetEvents(ctx, cursor, pollBatchSize)
+	if err != nil {
+		logrus.WithError(err).Error("Unable to get recorded events")
+		return cursor
+	}
+
+	dispatched := cursor
+	for _, event := range events {
+		if err := d.dispatch(ctx, event); err != nil {
+			if ctx.Err() == nil {
+				logrus.WithError(err).WithField("event", event.Type).Error("Unable to dispatch event to webhooks")
+			}
+			break
+		}
+		dispatched = event.ID
+	}
+	if dispatched == cursor {
+		return cursor
+	}
+
+	if err := d.repo.SetEventCursor(ctx, cursorName, dispatched); err != nil {
+		logrus.WithError(err).Error("Unable to record the last dispatched event")
+	}
+	return dispatched
+}
+
+// dispatch delivers an event to all webhooks at once, returning when every delivery has succeeded or run out of
+// attempts. An error is returned if the context is cancelled before then, so the event is dispatched again.
+func (d *Dispatcher) dispatch(ctx context.Context, event babynames.Event) error {
+	webhooks, err := d.repo.GetWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	p := &payload{
+		Event:      event.Type,
+		Name:       event.Name,
+		Count:      event.Count,
+		OccurredAt: event.At,
+	}
+	if event.Type != babynames.ImportCompletedEvent {
+		p.Role = babynames.RoleName(event.Role)
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "Unable to encode webhook payload")
+	}
+
+	var deliveries sync.WaitGroup
+	for _, webhook := range webhooks {
+		deliveries.Add(1)
+		go func(webhook babynames.Webhook) {
+			defer deliveries.Done()
+			d.deliver(ctx, webhook, event.Type, body)
+		}(webhook)
+	}
+	deliveries.Wait()
+	return ctx.Err()
+}
+
+// deliver posts a payload to a webhook, retrying with exponential backoff until it succeeds or runs out of
+// attempts. Every attempt is recorded in the delivery log.
+func (d *Dispatcher) deliver(ctx context.Context, webhook babynames.Webhook, eventType babynames.EventType, body []byte) {
+	backoff := initialBackoff
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		statusCode, err := d.post(ctx, webhook, eventType, body)
+
+		delivery := babynames.WebhookDelivery{
+			WebhookID:   webhook.ID,
+			EventType:   eventType,
+			Payload:     string(body),
+			Attempt:     attempt,
+			StatusCode:  statusCode,
+			Succeeded:   err == nil,
+			AttemptedAt: time.Now(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		if err := d.repo.LogWebhookDelivery(ctx, delivery); err != nil {
+			logrus.WithError(err).WithField("webhook_id", delivery.WebhookID).Error("Unable to log webhook delivery")
+		}
+
+		if delivery.Succeeded || attempt == maxAttempts {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, webhook babynames.Webhook, eventType babynames.EventType, body []byte) (int, error) {
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrap(err, "Unable to create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Babynames-Webhook")
+	req.Header.Set("X-Babynames-Event", string(eventType))
+	timestamp := time.Now().Unix()
+	req.Header.Set("X-Babynames-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Babynames-Signature", Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("Unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}